@@ -0,0 +1,240 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// checkCommand checks only the files it is given, on the command line or as
+// a NUL-separated list such as `git diff --name-only -z` prints, against the
+// overrides and LICENSE file of the project they are in.
+func checkCommand(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	all := flags.Bool("a", false, "Print all files and their licenses, not just problematic files.")
+	format := flags.String("format", "text", "Report format: text or json.")
+	list := flags.String("files", "", "Also check the NUL-separated paths in this file, or - for stdin, relative to the top of the git work tree.")
+	staged := flags.Bool("staged", false, "Check the staged content of the files with staged changes, or of those given.")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: weasel check [-a] [-format text|json] [-staged] [-files file|-] [path...]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	writeReport, ok := reportFormats[*format]
+	if !ok {
+		fmt.Println("Unknown report format: " + *format)
+		return 1
+	}
+
+	paths := flags.Args()
+	if *list != `` {
+		var b []byte
+		var err error
+		if *list == `-` {
+			b, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			b, err = ioutil.ReadFile(*list)
+		}
+		if err != nil {
+			fmt.Println("Cannot read file list: " + err.Error())
+			return 1
+		}
+		// The listed paths are relative to the top of the git work tree,
+		// as git prints them, if there is one.
+		top, err := exec.Command(`git`, `rev-parse`, `--show-toplevel`).Output()
+		for _, p := range bytes.Split(b, []byte{0}) {
+			if len(p) == 0 {
+				continue
+			}
+			name := string(p)
+			if err == nil && !filepath.IsAbs(name) {
+				name = filepath.Join(strings.TrimSpace(string(top)), name)
+			}
+			paths = append(paths, name)
+		}
+	}
+
+	// The paths are relative to the working directory, which is left for
+	// the root of the project.
+	var abs []string
+	for _, p := range paths {
+		a, err := filepath.Abs(p)
+		if err != nil {
+			fmt.Printf("Unable to get absolute path for %s: %v\n", p, err)
+			return 1
+		}
+		abs = append(abs, a)
+	}
+	if _, err := enterProject(`.`); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	if err := loadProject(); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		fmt.Println("Unable to get working directory: " + err.Error())
+		return 1
+	}
+
+	var names []string
+	for _, a := range abs {
+		name, err := filepath.Rel(root, a)
+		if err != nil || name == `..` || strings.HasPrefix(name, `../`) {
+			fmt.Printf("%s is not inside the project at %s!\n", a, root)
+			return 1
+		}
+		names = append(names, name)
+	}
+
+	var files map[string][]License
+	var copyrights map[string][]Copyright
+	var readLicenseFile func(licPath string) []License
+	if *staged {
+		x, err := newIndexContents()
+		if err != nil {
+			fmt.Println(err.Error())
+			return 1
+		}
+		defer x.Close()
+		files, copyrights, err = checkStaged(x, names)
+		if err != nil {
+			fmt.Println(err.Error())
+			return 1
+		}
+		readLicenseFile = func(licPath string) []License {
+			b, ok, err := x.read(licPath)
+			if err != nil || !ok || Ignored(licPath) {
+				return nil
+			}
+			return classifyWith(licPath, int64(len(b)), x.licenses)
+		}
+	} else {
+		files, copyrights = checkFiles(names)
+		readLicenseFile = func(licPath string) []License {
+			if info, err := os.Lstat(licPath); err == nil && info.Mode().IsRegular() && !Ignored(licPath) {
+				return classify(licPath, info)
+			}
+			return nil
+		}
+	}
+
+	// Files inherit from LICENSE files that weren't listed too.
+	licenseFiles := make(map[string][]License)
+	resolveLicenses(files, func(licPath string) []License {
+		if lics, ok := files[licPath]; ok {
+			return lics
+		}
+		lics, ok := licenseFiles[licPath]
+		if !ok {
+			lics = readLicenseFile(licPath)
+			licenseFiles[licPath] = lics
+		}
+		return lics
+	})
+
+	report := buildReportFor(root, files, copyrights, false)
+	writeReport(os.Stdout, report, !*all)
+	if report.Failed {
+		if *staged {
+			fmt.Fprintln(os.Stderr, "The staged files above fail the license check. Run `weasel explain <file>` to see why.")
+		}
+		return 1
+	}
+	return 0
+}
+
+// checkFiles classifies the files in names, which may be directories, and
+// finds their copyright statements. Paths that no longer exist, such as
+// deleted files in a diff, have nothing to check.
+func checkFiles(names []string) (map[string][]License, map[string][]Copyright) {
+	infos := make(map[string]os.FileInfo)
+	var found []string
+	for _, name := range names {
+		filepath.Walk(name, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if filepath.Base(name) == `.git` {
+				return filepath.SkipDir
+			}
+			if info.IsDir() || (info.Mode()&os.ModeSymlink) != 0 || Ignored(name) || isReuseMetadata(name) || isCustomLicenseText(name) {
+				return nil
+			}
+			if _, ok := infos[name]; !ok {
+				infos[name] = info
+				found = append(found, name)
+			}
+			return nil
+		})
+	}
+
+	files := classifyAll(found, func(name string) []License { return classify(name, infos[name]) })
+	copyrights := make(map[string][]Copyright)
+	for _, name := range found {
+		if c := copyrightsOf(name, infos[name]); len(c) != 0 {
+			copyrights[name] = c
+		}
+	}
+	return files, copyrights
+}
+
+// checkStaged classifies the staged content of the files with staged
+// changes, limited to those in names if there are any, and finds their
+// copyright statements, so that files with unstaged changes are judged as
+// they will be committed.
+func checkStaged(x *indexContents, names []string) (map[string][]License, map[string][]Copyright, error) {
+	staged, err := stagedFiles(names)
+	if err != nil {
+		return nil, nil, err
+	}
+	sizes := make(map[string]int64)
+	var found []string
+	for _, name := range staged {
+		if Ignored(name) || isReuseMetadata(name) || isCustomLicenseText(name) {
+			continue
+		}
+		b, ok, err := x.read(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			sizes[name] = int64(len(b))
+			found = append(found, name)
+		}
+	}
+
+	files := classifyAll(found, func(name string) []License { return classifyWith(name, sizes[name], x.licenses) })
+	copyrights := make(map[string][]Copyright)
+	for _, name := range found {
+		if c := copyrightsWith(name, sizes[name], x.copyrights); len(c) != 0 {
+			copyrights[name] = c
+		}
+	}
+	return files, copyrights, nil
+}
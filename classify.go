@@ -0,0 +1,102 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// classifyFormats are the formats the result of `weasel classify` can be
+// written in.
+var classifyFormats = map[string]func(w io.Writer, r ClassifyReport){
+	"text": writeClassifyText,
+	"json": writeClassifyJSON,
+}
+
+// ClassifyReport is the result of classifying a stream.
+type ClassifyReport struct {
+	Name     string         `json:"name"`
+	Licenses []LicenseMatch `json:"licenses"`
+}
+
+// classifyCommand classifies the content of stdin, as the contents of a file
+// named by -name, and prints the licenses found with their confidence. It
+// fails if no license is found.
+func classifyCommand(args []string) int {
+	flags := flag.NewFlagSet("classify", flag.ExitOnError)
+	name := flags.String("name", "stdin", "Classify the content as the contents of a file with this name, such as LICENSE.")
+	format := flags.String("format", "text", "Report format: text or json.")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: weasel classify [-name name] [-format text|json] < file")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 1
+	}
+	writeReport, ok := classifyFormats[*format]
+	if !ok {
+		fmt.Println("Unknown report format: " + *format)
+		return 1
+	}
+
+	b, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Println("Cannot read stdin: " + err.Error())
+		return 1
+	}
+	matches, err := contentMatches(*name, b)
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	if matches == nil {
+		matches = []LicenseMatch{}
+	}
+
+	writeReport(os.Stdout, ClassifyReport{Name: *name, Licenses: matches})
+	if len(matches) == 0 {
+		return 1
+	}
+	return 0
+}
+
+// writeClassifyText writes each license found with its confidence, or
+// Unknown if none was.
+func writeClassifyText(w io.Writer, r ClassifyReport) {
+	if len(r.Licenses) == 0 {
+		fmt.Fprintf(w, "%40s\n", "Unknown")
+	}
+	for _, m := range r.Licenses {
+		fmt.Fprintf(w, "%40s %.2f\n", m.License, m.Confidence)
+	}
+}
+
+func writeClassifyJSON(w io.Writer, r ClassifyReport) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(``, `  `)
+	enc.Encode(r)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// configFile is the optional project configuration file, found in the root
+// of the project.
+const configFile = `.weasel.json`
+
+// Config is the project configuration read from configFile.
+type Config struct {
+	// License is the SPDX identifier of the project's own license.
+	License string `json:"license"`
+	// Header selects the license header inserted by `weasel fix`: "apache"
+	// for the Apache-2.0 boilerplate, "asf" for the ASF source header, or
+	// "spdx" for a short SPDX-License-Identifier line.
+	Header string `json:"header"`
+	// Holder is the copyright holder named in inserted headers. No copyright
+	// line is written if it is empty.
+	Holder string `json:"holder"`
+	// Licenses are additional license texts, or directories of them, to add
+	// to the license database, as in customLicenseDir.
+	Licenses []string `json:"licenses"`
+	// Copyright is the copyright statement required of first-party files.
+	// No statement is required if it is absent.
+	Copyright *CopyrightPolicy `json:"copyright"`
+	// Large is how files too large to classify whole are classified.
+	Large LargeFiles `json:"large"`
+	// Reuse marks the project as following the REUSE specification, so the
+	// texts in its LICENSES directory are checked, even if it has neither
+	// REUSE.toml nor .reuse/dep5.
+	Reuse bool `json:"reuse"`
+}
+
+var defaultConfig = Config{
+	License: `Apache-2.0`,
+	Header:  `apache`,
+	Large:   defaultLargeFiles,
+}
+
+var config = defaultConfig
+
+// loadConfig reads configFile from the working directory, if it exists.
+func loadConfig() error {
+	c, err := readConfig(configFile, defaultConfig, `.`)
+	if err != nil {
+		config = defaultConfig
+		return err
+	}
+	config = c
+	return nil
+}
+
+// readConfig reads the configuration of the project in dir from the config
+// file at name, if it exists, over base.
+func readConfig(name string, base Config, dir string) (Config, error) {
+	return readConfigWith(name, base, dir, ioutil.ReadFile)
+}
+
+// readConfigWith reads a configuration as readConfig does, with readFile.
+func readConfigWith(name string, base Config, dir string, readFile func(string) ([]byte, error)) (Config, error) {
+	c := base
+	if c.Copyright != nil {
+		policy := *c.Copyright
+		c.Copyright = &policy
+	}
+	b, err := readFile(name)
+	if err != nil && !os.IsNotExist(err) {
+		return c, fmt.Errorf("Cannot read %s: %v", name, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, &c); err != nil {
+			return c, fmt.Errorf("Malformed %s: %v", name, err)
+		}
+	}
+	if err := c.Large.validate(); err != nil {
+		return c, fmt.Errorf("Malformed %s: %v", name, err)
+	}
+	if c.Copyright != nil {
+		if err := c.Copyright.compile(c, dir); err != nil {
+			return c, fmt.Errorf("Malformed %s: %v", name, err)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,307 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Copyright is a copyright statement found in a file.
+type Copyright struct {
+	// Statement is the whole statement, as written.
+	Statement string `json:"statement"`
+	// Years are the years or year ranges of the statement, such as
+	// "2015, 2017-2019", if any.
+	Years  string `json:"years,omitempty"`
+	Holder string `json:"holder"`
+}
+
+var (
+	// copyrightPattern matches a copyright statement at the start of a line:
+	// `Copyright`, `(c)`, `©` or `SPDX-FileCopyrightText:`, then any further
+	// symbols, the years, and the holder.
+	copyrightPattern = regexp.MustCompile(`(?i)^(spdx-filecopyrighttext:|copyright\b|\(c\)|©)[\s:]*((?:(?:copyright\b|\(c\)|©)[\s:]*)*)((?:(?:\d{4}(?:\s*[-–]\s*(?:\d{4}|present))?)[\s,]*)*)(.*)$`)
+
+	// commentPrefix matches the comment markers and decoration that may
+	// precede a copyright statement.
+	commentPrefix = regexp.MustCompile(`^[\s/*#;!%"'-]*(?:<!--|\{-|\(\*|--|rem\b|dnl\b)?[\s/*#;!%"'-]*`)
+
+	// holderSuffix matches what may follow the holder on the same line:
+	// the closing of a comment, trailing punctuation, or a reservation of
+	// rights.
+	holderSuffix = regexp.MustCompile(`(?i)(?:[\s.,;]*all rights reserved)?[\s.,;]*(?:\*/|-->|-\}|\*\)|")?[\s.,;]*$`)
+)
+
+// parseCopyright returns the copyright statement on a line, if there is one.
+// Statements must have a symbol, a year or an SPDX tag, to tell them from
+// mentions such as "the above copyright notice", and must name a holder.
+func parseCopyright(line string) (Copyright, bool) {
+	line = strings.TrimSpace(commentPrefix.ReplaceAllString(line, ``))
+	m := copyrightPattern.FindStringSubmatch(line)
+	if m == nil {
+		return Copyright{}, false
+	}
+	switch strings.ToLower(m[1]) {
+	case `copyright`:
+		if m[2] == `` && m[3] == `` {
+			return Copyright{}, false
+		}
+	case `(c)`, `©`:
+		if m[3] == `` { // Most likely an enumeration, as in "(c) You must retain..."
+			return Copyright{}, false
+		}
+	}
+
+	holder := strings.TrimSpace(strings.TrimPrefix(trimHolder(m[4]), `by `))
+	if holder == `` || strings.ContainsAny(holder[:1], `<[{%$`) {
+		return Copyright{}, false
+	}
+	return Copyright{
+		Statement: trimHolder(line),
+		Years:     strings.TrimRight(strings.TrimSpace(m[3]), `,`),
+		Holder:    holder,
+	}, true
+}
+
+// trimHolder removes holderSuffix from s, keeping the period of a final
+// abbreviation such as "Inc.".
+func trimHolder(s string) string {
+	s = strings.TrimSpace(s)
+	trimmed := strings.TrimSpace(holderSuffix.ReplaceAllString(s, ``))
+	words := strings.Fields(trimmed)
+	if len(words) != 0 && holderAbbreviations[strings.ToLower(words[len(words)-1])] && strings.HasPrefix(s[len(trimmed):], `.`) {
+		return trimmed + `.`
+	}
+	return trimmed
+}
+
+// holderAbbreviations are the abbreviations that commonly end the names of
+// copyright holders.
+var holderAbbreviations = map[string]bool{
+	`co`: true, `corp`: true, `inc`: true, `ltd`: true,
+}
+
+// taggedCopyright returns the copyright statement in the value of a tag such
+// as `SPDX-FileCopyrightText`, or a line of a DEP-5 `Copyright:` field, in
+// which the word "Copyright" is optional.
+func taggedCopyright(text string) Copyright {
+	if c, ok := parseCopyright(text); ok {
+		return c
+	}
+	if c, ok := parseCopyright(`Copyright ` + text); ok {
+		c.Statement = text
+		return c
+	}
+	return Copyright{Statement: text, Holder: text}
+}
+
+// parseCopyrights returns the distinct copyright statements in b.
+func parseCopyrights(b []byte) []Copyright {
+	var copyrights []Copyright
+	seen := make(map[string]bool)
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Buffer(nil, len(b)+1)
+	for s.Scan() {
+		if c, ok := parseCopyright(s.Text()); ok && !seen[c.Statement] {
+			seen[c.Statement] = true
+			copyrights = append(copyrights, c)
+		}
+	}
+	return copyrights
+}
+
+// fileCopyrights returns the copyright statements in a file. Files the
+// classifier reads are searched whole; others, like files with SPDX
+// identifiers, only in the windows searched for the identifiers.
+func fileCopyrights(name string) ([]Copyright, error) {
+	head, tail, err := spdxWindows(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(spdxLicenseSearch(head)) == 0 && len(spdxLicenseSearch(tail)) == 0 {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		if fi.Size() <= projectFor(name).Config.Large.Limit {
+			n := inFlight.acquire(fi.Size())
+			defer inFlight.release(n)
+			b, err := ioutil.ReadFile(name)
+			if err != nil {
+				return nil, err
+			}
+			return parseCopyrights(b), nil
+		}
+	}
+	return windowsCopyrights(head, tail), nil
+}
+
+// windowsCopyrights returns the distinct copyright statements in the windows
+// of a file searched for SPDX identifiers.
+func windowsCopyrights(head []byte, tail []byte) []Copyright {
+	copyrights := parseCopyrights(head)
+	for _, c := range parseCopyrights(tail) {
+		if !containsCopyright(copyrights, c) {
+			copyrights = append(copyrights, c)
+		}
+	}
+	return copyrights
+}
+
+// contentCopyrights is fileCopyrights for content that isn't in a file, such
+// as a blob in git.
+func contentCopyrights(name string, content []byte) ([]Copyright, error) {
+	head, tail, err := readSPDXWindows(name, bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+	if len(spdxLicenseSearch(head)) == 0 && len(spdxLicenseSearch(tail)) == 0 && int64(len(content)) <= projectFor(name).Config.Large.Limit {
+		return parseCopyrights(content), nil
+	}
+	return windowsCopyrights(head, tail), nil
+}
+
+// copyrightsOf returns the copyright statements of a file, from its contents
+// or its REUSE `.license` sidecar, and any REUSE annotation covering it.
+func copyrightsOf(name string, info os.FileInfo) []Copyright {
+	return copyrightsWith(name, info.Size(), fileCopyrights)
+}
+
+// copyrightsWith is copyrightsOf for a file of the given size, whose
+// contents, or those of its sidecar, are read by readCopyrights.
+func copyrightsWith(name string, size int64, readCopyrights func(source string) ([]Copyright, error)) []Copyright {
+	source, annotation := reuseSource(name), reuseAnnotationFor(name)
+	var found []Copyright
+	if (size != 0 || source != name) && !annotation.overrides() {
+		found, _ = readCopyrights(source)
+	}
+	return annotation.copyrights(found)
+}
+
+func containsCopyright(copyrights []Copyright, c Copyright) bool {
+	for _, have := range copyrights {
+		if have.Statement == c.Statement {
+			return true
+		}
+	}
+	return false
+}
+
+// HolderCount is the number of files naming a copyright holder.
+type HolderCount struct {
+	Holder string `json:"holder"`
+	Files  int    `json:"files"`
+}
+
+// copyrightHolders counts the files naming each distinct copyright holder,
+// most common first. Holders that differ only in case are counted together,
+// under the first spelling found.
+func copyrightHolders(copyrights map[string][]Copyright) []HolderCount {
+	var names []string
+	for name := range copyrights {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	index := make(map[string]int)
+	var holders []HolderCount
+	for _, name := range names {
+		counted := make(map[string]bool)
+		for _, c := range copyrights[name] {
+			key := strings.ToLower(c.Holder)
+			if counted[key] {
+				continue
+			}
+			counted[key] = true
+			i, ok := index[key]
+			if !ok {
+				i = len(holders)
+				index[key] = i
+				holders = append(holders, HolderCount{Holder: c.Holder})
+			}
+			holders[i].Files++
+		}
+	}
+	sort.SliceStable(holders, func(i, j int) bool {
+		return holders[i].Files > holders[j].Files
+	})
+	return holders
+}
+
+// copyrightsCommand prints the distinct copyright holders in the project,
+// with the number of files naming each.
+func copyrightsCommand(args []string) int {
+	flags := flag.NewFlagSet("copyrights", flag.ExitOnError)
+	var format string
+	flags.StringVar(&format, "format", "text", "Output format: text or json.")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: weasel copyrights [-format text|json] [dir]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() > 1 || (format != `text` && format != `json`) {
+		flags.Usage()
+		return 1
+	}
+
+	dir := `.`
+	if flags.NArg() == 1 {
+		dir = flags.Arg(0)
+	}
+	dir, err := enterProject(dir)
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	if err := loadProject(); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	_, copyrights, err := scan(dir)
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	writeHolders(os.Stdout, format, copyrightHolders(copyrights))
+	return 0
+}
+
+func writeHolders(w io.Writer, format string, holders []HolderCount) {
+	if format == `json` {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent(``, `  `)
+		enc.Encode(holders)
+		return
+	}
+	for _, h := range holders {
+		fmt.Fprintf(w, "%6d %s\n", h.Files, h.Holder)
+	}
+}
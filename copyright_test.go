@@ -0,0 +1,93 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCopyright(t *testing.T) {
+	tests := []struct {
+		line string
+		want Copyright
+		ok   bool
+	}{
+		{`Copyright 2026 Comcast Corporation`, Copyright{`Copyright 2026 Comcast Corporation`, `2026`, `Comcast Corporation`}, true},
+		{`// Copyright (c) 2015, 2017-2019 Foo Inc. All rights reserved.`, Copyright{`Copyright (c) 2015, 2017-2019 Foo Inc.`, `2015, 2017-2019`, `Foo Inc.`}, true},
+		{` * Copyright © 2020 – present The Bar Authors`, Copyright{`Copyright © 2020 – present The Bar Authors`, `2020 – present`, `The Bar Authors`}, true},
+		{`# (c) 2019 Baz Ltd.`, Copyright{`(c) 2019 Baz Ltd.`, `2019`, `Baz Ltd.`}, true},
+		{`© 2021 Qux`, Copyright{`© 2021 Qux`, `2021`, `Qux`}, true},
+		{`<!-- Copyright 2022 Quux -->`, Copyright{`Copyright 2022 Quux`, `2022`, `Quux`}, true},
+		{`SPDX-FileCopyrightText: 2023 Jane Doe <jane@example.com>`, Copyright{`SPDX-FileCopyrightText: 2023 Jane Doe <jane@example.com>`, `2023`, `Jane Doe <jane@example.com>`}, true},
+		{`Copyright (C) by The Authors`, Copyright{`Copyright (C) by The Authors`, ``, `The Authors`}, true},
+		{`-- Copyright: 2024 Corge Co.`, Copyright{`Copyright: 2024 Corge Co.`, `2024`, `Corge Co.`}, true},
+		{`/* Copyright 2025 Grault */`, Copyright{`Copyright 2025 Grault`, `2025`, `Grault`}, true},
+
+		// Mentions of copyright that aren't statements.
+		{`the above copyright notice and this permission notice`, Copyright{}, false},
+		{`Copyright notice must be retained.`, Copyright{}, false},
+		{`(c) You must retain, in the Source form of any Derivative Works`, Copyright{}, false},
+		{`Copyright 2020`, Copyright{}, false},
+		{`Copyright (c) <year> <copyright holders>`, Copyright{}, false},
+		{`Copyright [yyyy] [name of copyright owner]`, Copyright{}, false},
+		{`package main`, Copyright{}, false},
+	}
+
+	for _, test := range tests {
+		got, ok := parseCopyright(test.line)
+		if got != test.want || ok != test.ok {
+			t.Errorf("parseCopyright(%q) = %+v, %v, want %+v, %v", test.line, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestTrimHolder(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{`Foo`, `Foo`},
+		{`Foo.`, `Foo`},
+		{`Foo, Inc.`, `Foo, Inc.`},
+		{`Foo Corp. All rights reserved.`, `Foo Corp.`},
+		{`Foo Ltd.;`, `Foo Ltd.`},
+		{`Foo Inc. */`, `Foo Inc.`},
+		{`Foo -->`, `Foo`},
+		{`The Authors, all rights reserved`, `The Authors`},
+		{`  Foo  `, `Foo`},
+	}
+
+	for _, test := range tests {
+		if got := trimHolder(test.s); got != test.want {
+			t.Errorf("trimHolder(%q) = %q, want %q", test.s, got, test.want)
+		}
+	}
+}
+
+func TestParseCopyrights(t *testing.T) {
+	content := []byte("/*\nCopyright 2019 Foo\nCopyright 2019 Foo\n * Copyright 2020 Bar\n*/\n// the copyright holder\n")
+	want := []Copyright{
+		{`Copyright 2019 Foo`, `2019`, `Foo`},
+		{`Copyright 2020 Bar`, `2020`, `Bar`},
+	}
+	if got := parseCopyrights(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCopyrights() = %+v, want %+v", got, want)
+	}
+}
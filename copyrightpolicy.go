@@ -0,0 +1,274 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// yearFormats are the formats of the years of a copyright statement that a
+// CopyrightPolicy can accept.
+var yearFormats = map[string]bool{
+	`single`: true, // 2020
+	`range`:  true, // 2019-2021, or 2019-present
+	`list`:   true, // 2017, 2019-2021
+	`none`:   true, // no year at all
+}
+
+// CopyrightPolicy is the copyright statement required of first-party files,
+// configured under `copyright` in configFile.
+type CopyrightPolicy struct {
+	// Holder is a regular expression that the holder of a copyright
+	// statement in each file must match. It defaults to the configured
+	// holder, and if neither is set any holder is accepted.
+	Holder string `json:"holder"`
+	// Years are the accepted formats of the years of the statement, from
+	// yearFormats. All but `none` are accepted by default.
+	Years []string `json:"years"`
+	// GitYear requires the years of the statement to include the year of the
+	// file's last commit, or the current year for files never committed.
+	GitYear bool `json:"gitYear"`
+	// Paths are the files the policy applies to, as patterns in the syntax of
+	// `@` lines. Later patterns override earlier ones, and patterns starting
+	// with `!` exclude files. By default, the policy applies to files under
+	// the project's own license, not inherited from a LICENSE file, that
+	// `weasel fix` can add a header to.
+	Paths []string `json:"paths"`
+
+	holder  *regexp.Regexp
+	license string // The license of the project the policy is for.
+	dir     string // The directory of the project the policy is for.
+}
+
+// compile checks the policy of the project in dir, configured by c, and
+// prepares its holder pattern.
+func (p *CopyrightPolicy) compile(c Config, dir string) error {
+	p.license, p.dir, p.holder = c.License, dir, nil
+	pattern := p.Holder
+	if pattern == `` && c.Holder != `` {
+		pattern = `^` + regexp.QuoteMeta(c.Holder) + `$`
+	}
+	if pattern != `` {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("Bad copyright holder pattern: %v", err)
+		}
+		p.holder = re
+	}
+
+	if len(p.Years) == 0 {
+		p.Years = []string{`single`, `range`, `list`}
+	}
+	for _, format := range p.Years {
+		if !yearFormats[format] {
+			return fmt.Errorf("Unknown copyright year format %q", format)
+		}
+	}
+	return nil
+}
+
+// appliesTo reports whether the policy applies to a file with the given
+// licenses. Paths are relative to the directory of the policy's project.
+func (p *CopyrightPolicy) appliesTo(name string, lics []License) bool {
+	if len(lics) == 1 && lics[0] == License(`Empty`) {
+		return false
+	}
+	if len(p.Paths) != 0 {
+		applies := false
+		for _, pattern := range p.Paths {
+			doc := DocLine{Pattern: strings.TrimPrefix(pattern, `!`), Negate: strings.HasPrefix(pattern, `!`)}
+			if doc.Matches(projectPath(p.dir, name)) {
+				applies = !doc.Negate
+			}
+		}
+		return applies
+	}
+
+	if _, ok := styleFor(name, nil); !ok {
+		return false
+	}
+	for _, lic := range lics {
+		if strings.Contains(string(lic), `~`) {
+			return false
+		}
+	}
+	for _, lic := range lics {
+		if licenseID(lic) == p.license {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns why the copyright statements of a file don't satisfy the
+// policy, or the empty string if they do or the policy doesn't apply.
+func (p *CopyrightPolicy) Check(name string, lics []License, copyrights []Copyright) string {
+	if !p.appliesTo(name, lics) {
+		return ``
+	}
+	if len(copyrights) == 0 {
+		return `no copyright statement`
+	}
+
+	// If no statement satisfies the policy, why each fails is reported.
+	var reasons []string
+	holders := 0
+	for _, c := range copyrights {
+		if p.holder != nil && !p.holder.MatchString(c.Holder) {
+			reasons = append(reasons, fmt.Sprintf("%q is not by a holder matching %s", c.Statement, p.holder))
+			continue
+		}
+		holders++
+		format := yearFormat(c.Years)
+		if !contains(p.Years, format) {
+			reasons = append(reasons, fmt.Sprintf("%q has years in %s format, expected %s", c.Statement, format, strings.Join(p.Years, ` or `)))
+			continue
+		}
+		if p.GitYear {
+			year := lastCommitYear(name)
+			if !yearsInclude(c.Years, year) {
+				reasons = append(reasons, fmt.Sprintf("%q does not include %d, the year of the last change", c.Statement, year))
+				continue
+			}
+		}
+		return ``
+	}
+	if holders == 0 {
+		return fmt.Sprintf("no copyright statement by a holder matching %s", p.holder)
+	}
+	return strings.Join(reasons, `; `)
+}
+
+// yearFormat returns the format of the years of a copyright statement, from
+// yearFormats.
+func yearFormat(years string) string {
+	switch {
+	case years == ``:
+		return `none`
+	case strings.Contains(years, `,`):
+		return `list`
+	case strings.ContainsAny(years, `-–`):
+		return `range`
+	}
+	return `single`
+}
+
+// yearsInclude reports whether the years of a copyright statement include
+// year.
+func yearsInclude(years string, year int) bool {
+	for _, r := range yearRanges(years) {
+		if r[0] <= year && year <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// yearRanges returns the first and last year of each year or year range of a
+// copyright statement, such as "2015, 2017-present".
+func yearRanges(years string) [][2]int {
+	var ranges [][2]int
+	for _, part := range strings.Split(years, `,`) {
+		bounds := strings.FieldsFunc(part, func(c rune) bool { return c == '-' || c == '–' })
+		if len(bounds) == 0 {
+			continue
+		}
+		from, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			continue
+		}
+		to := from
+		if len(bounds) > 1 {
+			last := strings.TrimSpace(bounds[len(bounds)-1])
+			if strings.EqualFold(last, `present`) {
+				to = time.Now().Year()
+			} else if to, err = strconv.Atoi(last); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, [2]int{from, to})
+	}
+	return ranges
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	commitYears     map[string]int
+	commitYearsOnce sync.Once
+)
+
+// lastCommitYear returns the year of the last commit changing name, or the
+// current year if it was never committed. The whole history is read once,
+// the first time it's needed.
+func lastCommitYear(name string) int {
+	commitYearsOnce.Do(loadCommitYears)
+	if year, ok := commitYears[name]; ok {
+		return year
+	}
+	return time.Now().Year()
+}
+
+func loadCommitYears() {
+	commitYears = make(map[string]int)
+	if !hasGit || tmpGitDir != `` {
+		return
+	}
+	// Paths are relative to the working directory, as the scan's are.
+	out, err := exec.Command(`git`, `-c`, `core.quotePath=false`, `log`, `--format=@%ad`, `--date=format:%Y`, `--name-only`, `--relative`, `--no-renames`).Output()
+	if err != nil {
+		return
+	}
+	commitYears = parseCommitYears(out)
+}
+
+// parseCommitYears returns the year of the latest commit changing each file
+// in the output of loadCommitYears's `git log`, newest commit first: a line
+// with `@` and the year of each commit, followed by the files it changed.
+func parseCommitYears(out []byte) map[string]int {
+	years := make(map[string]int)
+	var year int
+	s := bufio.NewScanner(bytes.NewReader(out))
+	for s.Scan() {
+		line := s.Text()
+		if strings.HasPrefix(line, `@`) {
+			year, _ = strconv.Atoi(line[1:])
+			continue
+		}
+		if _, ok := years[line]; line != `` && !ok {
+			years[line] = year
+		}
+	}
+	return years
+}
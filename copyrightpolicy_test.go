@@ -0,0 +1,203 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestYearFormat(t *testing.T) {
+	tests := []struct {
+		years string
+		want  string
+	}{
+		{``, `none`},
+		{`2020`, `single`},
+		{`2019-2021`, `range`},
+		{`2019 – present`, `range`},
+		{`2017, 2019-2021`, `list`},
+		{`2017, 2019`, `list`},
+	}
+
+	for _, test := range tests {
+		if got := yearFormat(test.years); got != test.want {
+			t.Errorf("yearFormat(%q) = %q, want %q", test.years, got, test.want)
+		}
+	}
+}
+
+func TestYearRanges(t *testing.T) {
+	now := time.Now().Year()
+	tests := []struct {
+		years string
+		want  [][2]int
+	}{
+		{``, nil},
+		{`2020`, [][2]int{{2020, 2020}}},
+		{`2019-2021`, [][2]int{{2019, 2021}}},
+		{`2019 – 2021`, [][2]int{{2019, 2021}}},
+		{`2015-present`, [][2]int{{2015, now}}},
+		{`2015 - Present`, [][2]int{{2015, now}}},
+		{`2015, 2017-2019`, [][2]int{{2015, 2015}, {2017, 2019}}},
+		{`2015,, 2017`, [][2]int{{2015, 2015}, {2017, 2017}}},
+		{`2015-soon`, nil},
+	}
+
+	for _, test := range tests {
+		if got := yearRanges(test.years); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("yearRanges(%q) = %v, want %v", test.years, got, test.want)
+		}
+	}
+}
+
+func TestYearsInclude(t *testing.T) {
+	tests := []struct {
+		years string
+		year  int
+		want  bool
+	}{
+		{`2020`, 2020, true},
+		{`2020`, 2021, false},
+		{`2015, 2017-2019`, 2018, true},
+		{`2015, 2017-2019`, 2016, false},
+		{`2015-present`, time.Now().Year(), true},
+		{``, 2020, false},
+	}
+
+	for _, test := range tests {
+		if got := yearsInclude(test.years, test.year); got != test.want {
+			t.Errorf("yearsInclude(%q, %d) = %v, want %v", test.years, test.year, got, test.want)
+		}
+	}
+}
+
+func TestCompileCopyrightPolicy(t *testing.T) {
+	tests := []struct {
+		policy CopyrightPolicy
+		holder string
+		years  []string
+		err    string
+	}{
+		{policy: CopyrightPolicy{}, years: []string{`single`, `range`, `list`}},
+		{policy: CopyrightPolicy{Years: []string{`none`}}, years: []string{`none`}},
+		{policy: CopyrightPolicy{Holder: `^Acme`}, holder: `^Acme`, years: []string{`single`, `range`, `list`}},
+		{policy: CopyrightPolicy{Years: []string{`decade`}}, err: `Unknown copyright year format "decade"`},
+		{policy: CopyrightPolicy{Holder: `(`}, err: `Bad copyright holder pattern`},
+	}
+
+	for _, test := range tests {
+		p := test.policy
+		err := p.compile(Config{License: `Apache-2.0`}, `.`)
+		if test.err != `` {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("compile(%+v) = %v, want an error containing %q", test.policy, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("compile(%+v) = %v", test.policy, err)
+			continue
+		}
+		holder := ``
+		if p.holder != nil {
+			holder = p.holder.String()
+		}
+		if holder != test.holder || !reflect.DeepEqual(p.Years, test.years) {
+			t.Errorf("compile(%+v) = holder %q, years %q, want %q, %q", test.policy, holder, p.Years, test.holder, test.years)
+		}
+	}
+
+	// The configured holder is the default.
+	p := CopyrightPolicy{}
+	if err := p.compile(Config{License: `Apache-2.0`, Holder: `Foo (Inc.)`}, `.`); err != nil || p.holder.String() != `^Foo \(Inc\.\)$` {
+		t.Errorf("compile with a configured holder = %v, %v, want ^Foo \\(Inc\\.\\)$", p.holder, err)
+	}
+}
+
+func TestCopyrightPolicyAppliesTo(t *testing.T) {
+	p := CopyrightPolicy{}
+	p.compile(Config{License: `Apache-2.0`}, `.`)
+	paths := CopyrightPolicy{Paths: []string{`src/**`, `!src/gen/**`}}
+	paths.compile(Config{License: `Apache-2.0`}, `sdk`)
+
+	tests := []struct {
+		policy *CopyrightPolicy
+		name   string
+		lics   []License
+		want   bool
+	}{
+		{&p, `main.go`, []License{`Apache-2.0`}, true},
+		{&p, `main.go`, []License{`Unknown!`}, false},
+		{&p, `main.go`, []License{`Empty`}, false},
+		{&p, `vendor/a.go`, []License{`Apache-2.0~`}, false},
+		{&p, `lib/a.go`, []License{`MIT!`, `Apache-2.0`}, true},
+		{&p, `image.png`, []License{`Apache-2.0`}, false},
+		{&paths, `sdk/src/a.go`, []License{`MIT`}, true},
+		{&paths, `sdk/src/gen/a.go`, []License{`MIT`}, false},
+		{&paths, `sdk/lib/a.go`, []License{`MIT`}, false},
+		{&paths, `sdk/src/a.go`, []License{`Empty`}, false},
+	}
+
+	for _, test := range tests {
+		if got := test.policy.appliesTo(test.name, test.lics); got != test.want {
+			t.Errorf("appliesTo(%q, %q) = %v, want %v", test.name, test.lics, got, test.want)
+		}
+	}
+}
+
+func TestCopyrightPolicyCheck(t *testing.T) {
+	p := CopyrightPolicy{Years: []string{`single`, `range`}}
+	p.compile(Config{License: `Apache-2.0`, Holder: `Me`}, `.`)
+	lics := []License{`Apache-2.0`}
+	me := Copyright{`Copyright 2020 Me`, `2020`, `Me`}
+	other := Copyright{`Copyright 2020 Other`, `2020`, `Other`}
+	list := Copyright{`Copyright 2019, 2020 Me`, `2019, 2020`, `Me`}
+
+	tests := []struct {
+		name       string
+		copyrights []Copyright
+		want       string
+	}{
+		{"none", nil, `no copyright statement`},
+		{"satisfied", []Copyright{me}, ``},
+		{"satisfied by any", []Copyright{other, list, me}, ``},
+		{"other holder", []Copyright{other}, `no copyright statement by a holder matching ^Me$`},
+		{"every reason", []Copyright{other, list}, `"Copyright 2020 Other" is not by a holder matching ^Me$; "Copyright 2019, 2020 Me" has years in list format, expected single or range`},
+	}
+
+	for _, test := range tests {
+		if got := p.Check(`main.go`, lics, test.copyrights); got != test.want {
+			t.Errorf("%s: Check() = %q, want %q", test.name, got, test.want)
+		}
+	}
+	if got := p.Check(`image.png`, lics, nil); got != `` {
+		t.Errorf("Check() of a file the policy doesn't apply to = %q, want none", got)
+	}
+}
+
+func TestParseCommitYears(t *testing.T) {
+	out := []byte("@2026\n\na.go\nsub/b.go\n@2024\n\na.go\nc.go\n@2020\n\nsub/b.go\nd b.go\n")
+	want := map[string]int{`a.go`: 2026, `sub/b.go`: 2026, `c.go`: 2024, `d b.go`: 2020}
+	if got := parseCommitYears(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCommitYears() = %v, want %v", got, want)
+	}
+}
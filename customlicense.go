@@ -0,0 +1,187 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/licenseclassifier"
+	"github.com/google/licenseclassifier/stringclassifier/searchset"
+)
+
+// customLicenseDir is the directory in the root of the project holding the
+// texts of licenses that aren't in the SPDX license list. Like the SPDX
+// licenses, each license is in a file named for its id, `<id>.txt`, and
+// its standard header, if any, is in `<id>.header.txt`.
+const customLicenseDir = `.licenses`
+
+// isCustomLicenseText reports whether name is the text of a custom license,
+// in customLicenseDir or the paths listed in the configuration. Like the
+// texts in LICENSES in a REUSE project, these aren't scanned.
+func isCustomLicenseText(name string) bool {
+	if !strings.HasSuffix(name, `.txt`) {
+		return false
+	}
+	name = filepath.Clean(name)
+	for _, p := range append([]string{customLicenseDir}, config.Licenses...) {
+		p = filepath.Clean(p)
+		if name == p || strings.HasPrefix(name, p+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadCustomLicenses adds the license texts in customLicenseDir and the
+// paths listed in the configuration to the classifier and the license texts.
+// The classifier is only rebuilt if there are custom licenses; otherwise,
+// the built-in one replaces that of any project loaded before.
+func loadCustomLicenses() error {
+	var files []string
+	for i, p := range append([]string{customLicenseDir}, config.Licenses...) {
+		fi, err := os.Stat(p)
+		if i == 0 && os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("Cannot read custom licenses: %v", err)
+		}
+		if !fi.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(p, `*.txt`))
+		if err != nil {
+			return fmt.Errorf("Cannot read custom licenses in %s: %v", p, err)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		setClassifier(builtinClassifier)
+		return nil
+	}
+
+	texts := make(map[string]string)
+	for _, name := range files {
+		if !strings.HasSuffix(name, `.txt`) {
+			return fmt.Errorf("Custom license %s must be named <id>.txt or <id>.header.txt", name)
+		}
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("Cannot read custom license: %v", err)
+		}
+		texts[strings.TrimSuffix(filepath.Base(name), `.txt`)] = string(b)
+	}
+
+	archive, err := mergeLicenseArchive(LicenseDBContents, texts)
+	if err != nil {
+		return fmt.Errorf("Cannot add custom licenses: %v", err)
+	}
+	c, err := licenseclassifier.New(classifierThreshold, licenseclassifier.ArchiveBytes(archive))
+	if err != nil {
+		return fmt.Errorf("Failed to initialize classifier with custom licenses: %v", err)
+	}
+	setClassifier(classifierState{classifier: c, archive: archive, texts: texts, near: newNearClassifier(archive)})
+	return nil
+}
+
+// mergeLicenseArchive returns a copy of the classifier's license archive
+// with texts added, replacing any licenses with the same ids. Texts are
+// normalized and hashed the same way the serializer used by dbmaker does.
+func mergeLicenseArchive(archive []byte, texts map[string]string) ([]byte, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	var out bytes.Buffer
+	gw := gzip.NewWriter(&out)
+	tw := tar.NewWriter(gw)
+
+	write := func(name string, b []byte) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(b))}); err != nil {
+			return err
+		}
+		_, err := tw.Write(b)
+		return err
+	}
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		ext := filepath.Ext(hdr.Name)
+		if _, ok := texts[strings.TrimSuffix(hdr.Name, ext)]; ok {
+			continue
+		}
+		if err := write(hdr.Name, b); err != nil {
+			return nil, err
+		}
+	}
+
+	var ids []string
+	for id := range texts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		str := licenseclassifier.TrimExtraneousTrailingText(texts[id])
+		for _, n := range licenseclassifier.Normalizers {
+			str = n(str)
+		}
+
+		var hash bytes.Buffer
+		if err := searchset.New(str, searchset.DefaultGranularity).Serialize(&hash); err != nil {
+			return nil, err
+		}
+		if err := write(id+`.txt`, []byte(str)); err != nil {
+			return nil, err
+		}
+		if err := write(id+`.hash`, hash.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -19,24 +19,37 @@ SPDX-License-Identifier: Apache-2.0
 package main
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
-	"path/filepath"
+	"sort"
 
-	"github.com/google/licenseclassifier/serializer"
+	"github.com/google/licenseclassifier"
+	"github.com/google/licenseclassifier/stringclassifier/searchset"
 )
 
 func main() {
-	if len(os.Args) != 3 {
+	if len(os.Args) != 4 {
 		bail()
 	}
 	dir := os.Args[1]
 	licensedb := os.Args[2]
-	if dir == `` || licensedb == `` {
+	licensedata := os.Args[3]
+	if dir == `` || licensedb == `` || licensedata == `` {
 		bail()
 	}
 
+	list, err := readLicenseList(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read SPDX license list data from %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Read %d licenses and exceptions from SPDX license list %s.\n", len(list.Metadata.Licenses), list.Metadata.ListVersion)
+
 	out, err := os.Create(licensedb)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Unable to create %s: %v\n", licensedb, err)
@@ -44,24 +57,104 @@ func main() {
 	}
 	defer out.Close()
 
-	var licenses []string
-	fis, err := ioutil.ReadDir(dir)
-	for _, fi := range fis {
-		absPath, err := filepath.Abs(filepath.Join(dir, fi.Name()))
-		if err != nil {
-			continue
-		}
-		licenses = append(licenses, absPath)
+	err = archiveLicenses(list.Texts, out)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to serialize licenses to %s: %v\n", licensedb, err)
+		os.Exit(1)
 	}
 
-	err = serializer.ArchiveLicenses(licenses, out)
+	data, err := os.Create(licensedata)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Unable to serialize licenses to %s: %v\n", licensedb, err)
+		fmt.Fprintf(os.Stderr, "Unable to create %s: %v\n", licensedata, err)
+		os.Exit(1)
+	}
+	defer data.Close()
+
+	err = archiveData(list, data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to archive license data to %s: %v\n", licensedata, err)
 		os.Exit(1)
 	}
 }
 
+// sortedIDs returns the keys of texts in order, so that the archives are
+// reproducible.
+func sortedIDs(texts map[string]string) []string {
+	var ids []string
+	for id := range texts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// archiveLicenses writes the classifier's archive of licenses: for each
+// license, its normalized text followed by the checksums of its substrings.
+// This is the format written by licenseclassifier's serializer.
+func archiveLicenses(texts map[string]string, w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	defer gw.Close()
+
+	tw := tar.NewWriter(gw)
+	for _, id := range sortedIDs(texts) {
+		str := licenseclassifier.TrimExtraneousTrailingText(texts[id])
+		for _, n := range licenseclassifier.Normalizers {
+			str = n(str)
+		}
+
+		var set bytes.Buffer
+		if err := searchset.New(str, searchset.DefaultGranularity).Serialize(&set); err != nil {
+			return err
+		}
+
+		if err := writeEntry(tw, id+`.txt`, []byte(str)); err != nil {
+			return err
+		}
+		if err := writeEntry(tw, id+`.hash`, set.Bytes()); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// archiveData writes the original, unnormalized text of each license, so
+// that weasel can reproduce it for LICENSE files, along with the metadata
+// of every license in metadata.json.
+func archiveData(list *licenseList, w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	defer gw.Close()
+
+	tw := tar.NewWriter(gw)
+	for _, id := range sortedIDs(list.Texts) {
+		if err := writeEntry(tw, id+`.txt`, []byte(list.Texts[id])); err != nil {
+			return err
+		}
+	}
+
+	metadata, err := json.MarshalIndent(list.Metadata, ``, "\t")
+	if err != nil {
+		return err
+	}
+	if err := writeEntry(tw, `metadata.json`, metadata); err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+func writeEntry(tw *tar.Writer, name string, b []byte) error {
+	hdr := &tar.Header{
+		Name: name,
+		Mode: 0644,
+		Size: int64(len(b)),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(b)
+	return err
+}
+
 func bail() {
-	fmt.Fprintf(os.Stderr, "Usage: %s <dir> <licensedb>\n")
+	fmt.Fprintf(os.Stderr, "Usage: %s <license-list-data> <licensedb> <licensedata>\n", os.Args[0])
 	os.Exit(1)
 }
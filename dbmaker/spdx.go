@@ -0,0 +1,145 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// Metadata describes the licenses in the database. It's stored as
+// metadata.json in the license data archive, and must match the Metadata
+// type weasel reads it into.
+type Metadata struct {
+	ListVersion string                 `json:"licenseListVersion"`
+	Licenses    map[string]LicenseInfo `json:"licenses"`
+}
+
+// LicenseInfo is the metadata of a single license or exception.
+type LicenseInfo struct {
+	Name        string   `json:"name"`
+	OSIApproved bool     `json:"osiApproved,omitempty"`
+	FSFLibre    bool     `json:"fsfLibre,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+	Exception   bool     `json:"exception,omitempty"`
+	SeeAlso     []string `json:"seeAlso,omitempty"`
+}
+
+// licenseList is everything read from a license-list-data checkout.
+type licenseList struct {
+	Metadata Metadata
+	// Texts holds the text of each license and exception by id, and the
+	// standard header of each license that has one by `<id>.header`.
+	Texts map[string]string
+}
+
+// readLicenseList reads the JSON files of a local checkout of
+// https://github.com/spdx/license-list-data.
+func readLicenseList(dir string) (*licenseList, error) {
+	list := &licenseList{
+		Metadata: Metadata{Licenses: make(map[string]LicenseInfo)},
+		Texts:    make(map[string]string),
+	}
+
+	var licenses struct {
+		ListVersion string `json:"licenseListVersion"`
+		Licenses    []struct {
+			LicenseID string `json:"licenseId"`
+		} `json:"licenses"`
+	}
+	if err := readJSON(filepath.Join(dir, `json`, `licenses.json`), &licenses); err != nil {
+		return nil, err
+	}
+	list.Metadata.ListVersion = licenses.ListVersion
+
+	for _, l := range licenses.Licenses {
+		var details struct {
+			Name                  string   `json:"name"`
+			LicenseText           string   `json:"licenseText"`
+			StandardLicenseHeader string   `json:"standardLicenseHeader"`
+			IsOsiApproved         bool     `json:"isOsiApproved"`
+			IsFsfLibre            bool     `json:"isFsfLibre"`
+			IsDeprecatedLicenseID bool     `json:"isDeprecatedLicenseId"`
+			SeeAlso               []string `json:"seeAlso"`
+		}
+		if err := readJSON(filepath.Join(dir, `json`, `details`, l.LicenseID+`.json`), &details); err != nil {
+			return nil, err
+		}
+
+		list.Metadata.Licenses[l.LicenseID] = LicenseInfo{
+			Name:        details.Name,
+			OSIApproved: details.IsOsiApproved,
+			FSFLibre:    details.IsFsfLibre,
+			Deprecated:  details.IsDeprecatedLicenseID,
+			SeeAlso:     details.SeeAlso,
+		}
+		if details.LicenseText != `` {
+			list.Texts[l.LicenseID] = details.LicenseText
+		}
+		if details.StandardLicenseHeader != `` {
+			list.Texts[l.LicenseID+`.header`] = details.StandardLicenseHeader
+		}
+	}
+
+	var exceptions struct {
+		Exceptions []struct {
+			LicenseExceptionID string `json:"licenseExceptionId"`
+		} `json:"exceptions"`
+	}
+	if err := readJSON(filepath.Join(dir, `json`, `exceptions.json`), &exceptions); err != nil {
+		return nil, err
+	}
+
+	for _, e := range exceptions.Exceptions {
+		var details struct {
+			Name                  string   `json:"name"`
+			LicenseExceptionText  string   `json:"licenseExceptionText"`
+			IsDeprecatedLicenseID bool     `json:"isDeprecatedLicenseId"`
+			SeeAlso               []string `json:"seeAlso"`
+		}
+		if err := readJSON(filepath.Join(dir, `json`, `exceptions`, e.LicenseExceptionID+`.json`), &details); err != nil {
+			return nil, err
+		}
+
+		list.Metadata.Licenses[e.LicenseExceptionID] = LicenseInfo{
+			Name:       details.Name,
+			Deprecated: details.IsDeprecatedLicenseID,
+			Exception:  true,
+			SeeAlso:    details.SeeAlso,
+		}
+		if details.LicenseExceptionText != `` {
+			list.Texts[e.LicenseExceptionID] = details.LicenseExceptionText
+		}
+	}
+
+	return list, nil
+}
+
+func readJSON(name string, v interface{}) error {
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("Malformed %s: %v", name, err)
+	}
+	return nil
+}
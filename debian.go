@@ -0,0 +1,525 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// debianCopyrightFile is the copyright file of a Debian package. When it is
+// in the machine-readable format (DEP-5), its `Files:` paragraphs license the
+// files in which no license is found, and are checked against the others.
+const debianCopyrightFile = `debian/copyright`
+
+// dep5Format is the `Format:` of a machine-readable copyright file.
+const dep5Format = `https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/`
+
+// dep5Unknown is written in place of the licenses or copyrights of files for
+// which none were found.
+const dep5Unknown = `UNKNOWN`
+
+// debianLicenseIDs maps the lowercase short names of licenses used in Debian
+// copyright files to their SPDX identifiers, where they differ.
+var debianLicenseIDs = map[string]string{
+	`expat`:        `MIT`,
+	`bsd-2-clause`: `BSD-2-Clause`,
+	`bsd-3-clause`: `BSD-3-Clause`,
+	`bsd-4-clause`: `BSD-4-Clause`,
+	`gpl-1`:        `GPL-1.0-only`,
+	`gpl-1+`:       `GPL-1.0-or-later`,
+	`gpl-2`:        `GPL-2.0-only`,
+	`gpl-2+`:       `GPL-2.0-or-later`,
+	`gpl-3`:        `GPL-3.0-only`,
+	`gpl-3+`:       `GPL-3.0-or-later`,
+	`lgpl-2`:       `LGPL-2.0-only`,
+	`lgpl-2+`:      `LGPL-2.0-or-later`,
+	`lgpl-2.1`:     `LGPL-2.1-only`,
+	`lgpl-2.1+`:    `LGPL-2.1-or-later`,
+	`lgpl-3`:       `LGPL-3.0-only`,
+	`lgpl-3+`:      `LGPL-3.0-or-later`,
+	`agpl-3`:       `AGPL-3.0-only`,
+	`agpl-3+`:      `AGPL-3.0-or-later`,
+	`gfdl-1.2`:     `GFDL-1.2-only`,
+	`gfdl-1.2+`:    `GFDL-1.2-or-later`,
+	`gfdl-1.3`:     `GFDL-1.3-only`,
+	`gfdl-1.3+`:    `GFDL-1.3-or-later`,
+	`mpl-1.1`:      `MPL-1.1`,
+	`mpl-2.0`:      `MPL-2.0`,
+	`apache-2.0`:   `Apache-2.0`,
+	`zlib`:         `Zlib`,
+	`isc`:          `ISC`,
+}
+
+// debianRule is a `Files:` paragraph of debianCopyrightFile.
+type debianRule struct {
+	Regexps []*regexp.Regexp
+	Rule    overrideRule
+	// All is set for the `Files: *` paragraph, which describes the package
+	// as a whole.
+	All bool
+}
+
+// debianRules are the `Files:` paragraphs of the project's
+// debianCopyrightFile.
+var debianRules []debianRule
+
+// loadDebianCopyright reads the `Files:` paragraphs of debianCopyrightFile.
+// Copyright files that aren't machine-readable are ignored.
+func loadDebianCopyright() error {
+	debianRules = nil
+	f, err := os.Open(debianCopyrightFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Cannot read %s: %v", debianCopyrightFile, err)
+	}
+	defer f.Close()
+
+	debianRules, err = parseDebianCopyright(f)
+	return err
+}
+
+// parseDebianCopyright parses the `Files:` paragraphs of a machine-readable
+// debianCopyrightFile. A file that doesn't start with a `Format:` field
+// isn't machine-readable, and has none.
+func parseDebianCopyright(r io.Reader) ([]debianRule, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read %s: %v", debianCopyrightFile, err)
+	}
+	if first := strings.SplitN(string(b), "\n", 2)[0]; !strings.HasPrefix(strings.ToLower(first), `format:`) {
+		return nil, nil
+	}
+	paragraphs, err := parseDep5(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("Malformed %s: %v", debianCopyrightFile, err)
+	}
+	if len(paragraphs) == 0 || paragraphs[0].Field(`Format`) == `` {
+		return nil, nil
+	}
+
+	var rules []debianRule
+	for _, p := range paragraphs {
+		files := strings.Fields(p.Field(`Files`))
+		if len(files) == 0 {
+			continue
+		}
+		lic := dep5License(p.Field(`License`))
+		if lic == `` {
+			return nil, fmt.Errorf("Malformed %s: line %d: Files paragraph without a License", debianCopyrightFile, p.Line)
+		}
+		rule := debianRule{Rule: overrideRule{debianCopyrightFile, p.Line, `Files: ` + strings.Join(files, ` `), lic}}
+		for _, pattern := range files {
+			if pattern == `*` {
+				rule.All = true
+			}
+			re, err := dep5Pattern(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("Malformed %s: line %d: bad pattern %s: %v", debianCopyrightFile, p.Line, pattern, err)
+			}
+			rule.Regexps = append(rule.Regexps, re)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// debianRuleFor returns the paragraph of rules that gives name its license:
+// as in DEP-5, the last one matching it.
+func debianRuleFor(rules []debianRule, name string) (debianRule, bool) {
+	var last debianRule
+	found := false
+	for _, rule := range rules {
+		for _, re := range rule.Regexps {
+			if re.MatchString(name) {
+				last, found = rule, true
+				break
+			}
+		}
+	}
+	return last, found
+}
+
+// debianLicenses returns the license that rules give a file with no detected
+// licenses, or one found too large, or lics if it has other licenses. The
+// `Files: *` paragraph describes the whole package, as the root LICENSE file
+// does, so like it, it gives no file a license, and neither does an UNKNOWN
+// one.
+func debianLicenses(rules []debianRule, name string, lics []License) []License {
+	if len(lics) != 0 && !isTooLarge(lics) {
+		return lics
+	}
+	if rule, ok := debianRuleFor(rules, name); ok && !rule.All && rule.Rule.License != dep5Unknown {
+		return []License{rule.Rule.License}
+	}
+	return lics
+}
+
+// debianConflict returns how the paragraph of rules matching a file
+// conflicts with the file's licenses, or the empty string if none of its
+// licenses contradict the paragraph.
+func debianConflict(rules []debianRule, name string, lics []License) string {
+	rule, ok := debianRuleFor(rules, name)
+	if !ok || rule.Rule.License == dep5Unknown {
+		return ``
+	}
+	allowed := make(map[string]bool)
+	for _, word := range strings.Fields(string(rule.Rule.License)) {
+		allowed[strings.Trim(word, `()`)] = true
+	}
+	var others []string
+	for _, lic := range lics {
+		id := licenseID(lic)
+		if accepted(License(id)) || allowed[id] || id == string(rule.Rule.License) ||
+			strings.HasPrefix(id, `Unknown`) || strings.HasPrefix(id, `Error: `) || id == string(tooLarge) {
+			continue
+		}
+		others = append(others, id)
+	}
+	if len(others) == 0 {
+		return ``
+	}
+	return fmt.Sprintf("detected %s, but %s:%d says %s", strings.Join(others, `, `), debianCopyrightFile, rule.Rule.Line, rule.Rule.License)
+}
+
+// dep5License converts the license expression on the first line of a DEP-5
+// `License:` field to SPDX, such as "GPL-2+ or Expat" to
+// "GPL-2.0-or-later OR MIT".
+func dep5License(field string) License {
+	words := strings.Fields(strings.SplitN(field, "\n", 2)[0])
+	for i, word := range words {
+		switch lower := strings.ToLower(word); lower {
+		case `and`, `or`, `with`:
+			words[i] = strings.ToUpper(lower)
+		default:
+			if id, ok := debianLicenseIDs[lower]; ok {
+				words[i] = id
+			}
+		}
+	}
+	return License(strings.Join(words, ` `))
+}
+
+// dep5Command writes a machine-readable debian/copyright file describing the
+// scanned project, grouping its files by license and copyright holders.
+func dep5Command(args []string) int {
+	flags := flag.NewFlagSet("dep5", flag.ExitOnError)
+	var write bool
+	flags.BoolVar(&write, "w", false, "Write "+debianCopyrightFile+" instead of printing it.")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: weasel dep5 [-w]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if _, err := enterProject(`.`); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	if err := loadProject(); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	files, copyrights, err := scan(`.`)
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		fmt.Println("Unable to get working directory: " + err.Error())
+		return 1
+	}
+
+	groups := dep5Groups(files, copyrights)
+	for _, g := range groups {
+		if g.License == dep5Unknown {
+			fmt.Fprintf(os.Stderr, "%d file(s) have no known license, such as %s\n", len(g.Files), g.Files[0])
+		}
+	}
+
+	var out bytes.Buffer
+	writeDep5(&out, filepath.Base(root), groups)
+	if !write {
+		io.Copy(os.Stdout, &out)
+		return 0
+	}
+
+	if err := os.MkdirAll(path.Dir(debianCopyrightFile), 0755); err != nil {
+		fmt.Println("Cannot create debian directory: " + err.Error())
+		return 1
+	}
+	if err := ioutil.WriteFile(debianCopyrightFile, out.Bytes(), 0644); err != nil {
+		fmt.Println("Cannot write " + debianCopyrightFile + ": " + err.Error())
+		return 1
+	}
+	return 0
+}
+
+// dep5Group is a set of files with the same licenses and copyright holders,
+// written as one `Files:` paragraph.
+type dep5Group struct {
+	License   string   // DEP-5 license expression.
+	Copyright []string // One statement per holder, with the years of every file.
+	Files     []string
+	Patterns  []string
+
+	key     string
+	holders []string
+	years   map[string][]string // By lowercase holder.
+}
+
+// dep5Groups groups the scanned files by license and copyright holders, and
+// finds the patterns that match each group's files. The largest group comes
+// first, matching every file with `*`, and the other groups override it.
+// Ignored and empty files are left out.
+func dep5Groups(files map[string][]License, copyrights map[string][]Copyright) []*dep5Group {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	byKey := make(map[string]*dep5Group)
+	keys := make(map[string]string) // The group key of each file.
+	var groups []*dep5Group
+	for _, name := range names {
+		lics := files[name]
+		if _, ignore, _ := verdict(lics); ignore || (len(lics) == 1 && lics[0] == License(`Empty`)) {
+			continue
+		}
+
+		expr := dep5Expression(lics)
+		byHolder := make(map[string]Copyright)
+		var holders []string
+		for _, c := range copyrights[name] {
+			key := strings.ToLower(c.Holder)
+			if _, ok := byHolder[key]; !ok {
+				holders = append(holders, key)
+			}
+			byHolder[key] = c
+		}
+		sort.Strings(holders)
+
+		key := expr + "\n" + strings.Join(holders, "\n")
+		g, ok := byKey[key]
+		if !ok {
+			g = &dep5Group{License: expr, key: key, years: make(map[string][]string)}
+			for _, holder := range holders {
+				g.holders = append(g.holders, byHolder[holder].Holder)
+			}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		for _, holder := range holders {
+			if c := byHolder[holder]; c.Years != `` {
+				g.years[holder] = append(g.years[holder], c.Years)
+			}
+		}
+		g.Files = append(g.Files, name)
+		keys[name] = key
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].Files) != len(groups[j].Files) {
+			return len(groups[i].Files) > len(groups[j].Files)
+		}
+		return groups[i].key < groups[j].key
+	})
+	groups[0].Patterns = []string{`*`}
+
+	subtreeKeys := make(map[string]map[string]bool) // every key found below a directory
+	for name, key := range keys {
+		for dir := path.Dir(name); ; dir = path.Dir(dir) {
+			if subtreeKeys[dir] == nil {
+				subtreeKeys[dir] = make(map[string]bool)
+			}
+			subtreeKeys[dir][key] = true
+			if dir == `.` {
+				break
+			}
+		}
+	}
+	for _, g := range groups[1:] {
+		covered := make(map[string]bool)
+		for _, name := range g.Files {
+			pattern := dep5Escape(name)
+			for dir := path.Dir(name); dir != `.`; dir = path.Dir(dir) {
+				if len(subtreeKeys[dir]) == 1 {
+					pattern = dep5Escape(dir) + `/*`
+				}
+			}
+			if !covered[pattern] {
+				covered[pattern] = true
+				g.Patterns = append(g.Patterns, pattern)
+			}
+		}
+	}
+
+	for _, g := range groups {
+		for _, holder := range g.holders {
+			statement := holder
+			if years := mergeYears(g.years[strings.ToLower(holder)]); years != `` {
+				statement = years + ` ` + holder
+			}
+			g.Copyright = append(g.Copyright, statement)
+		}
+	}
+	return groups
+}
+
+// dep5Expression renders the licenses of a file as a DEP-5 license
+// expression. Licenses that couldn't be determined are dep5Unknown.
+func dep5Expression(lics []License) string {
+	var ids []string
+	for _, lic := range lics {
+		id := licenseID(lic)
+		if id == `` || strings.HasPrefix(id, `Unknown`) || strings.HasPrefix(id, `Error: `) {
+			return dep5Unknown
+		}
+		id = strings.NewReplacer(` OR `, ` or `, ` AND `, ` and `, ` WITH `, ` with `).Replace(id)
+		if len(lics) > 1 && strings.Contains(id, ` `) {
+			id = `(` + id + `)`
+		}
+		if !contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return dep5Unknown
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ` and `)
+}
+
+// dep5Escape quotes the characters that `Files:` patterns treat specially.
+// Whitespace, which separates patterns, is matched with `?`.
+func dep5Escape(name string) string {
+	var b strings.Builder
+	for _, c := range name {
+		switch {
+		case c == '*' || c == '?' || c == '\\':
+			b.WriteRune('\\')
+			b.WriteRune(c)
+		case c == ' ' || c == '\t':
+			b.WriteRune('?')
+		default:
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// mergeYears combines the years of several copyright statements into one
+// list of years and ranges, such as "2015, 2017-2019".
+func mergeYears(years []string) string {
+	var ranges [][2]int
+	for _, y := range years {
+		ranges = append(ranges, yearRanges(y)...)
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+
+	var merged [][2]int
+	for _, r := range ranges {
+		if n := len(merged); n != 0 && r[0] <= merged[n-1][1]+1 {
+			if r[1] > merged[n-1][1] {
+				merged[n-1][1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	var parts []string
+	for _, r := range merged {
+		if r[0] == r[1] {
+			parts = append(parts, fmt.Sprint(r[0]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", r[0], r[1]))
+		}
+	}
+	return strings.Join(parts, `, `)
+}
+
+// writeDep5 writes a machine-readable copyright file: the header, a `Files:`
+// paragraph for each group, and a stand-alone `License:` paragraph with the
+// text of each license used.
+func writeDep5(w io.Writer, name string, groups []*dep5Group) {
+	field := func(name string, values []string) {
+		fmt.Fprintf(w, "%s: %s\n", name, values[0])
+		for _, value := range values[1:] {
+			fmt.Fprintf(w, " %s\n", value)
+		}
+	}
+
+	fmt.Fprintf(w, "Format: %s\n", dep5Format)
+	fmt.Fprintf(w, "Upstream-Name: %s\n", name)
+
+	var ids []string
+	for _, g := range groups {
+		fmt.Fprintln(w)
+		field("Files", g.Patterns)
+		copyright := g.Copyright
+		if len(copyright) == 0 {
+			copyright = []string{dep5Unknown}
+		}
+		field("Copyright", copyright)
+		field("License", []string{g.License})
+
+		for _, id := range spdxExpressionIDs(g.License) {
+			if id != dep5Unknown && !contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintln(w)
+		text, ok := licenseText(id)
+		if !ok {
+			field("License", []string{id, fmt.Sprintf("(The license database has no text for %s. Add it here.)", id)})
+			continue
+		}
+		lines := []string{id}
+		for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+			if line = strings.TrimRight(line, " \t"); line == `` {
+				line = `.`
+			}
+			lines = append(lines, line)
+		}
+		field("License", lines)
+	}
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDep5(t *testing.T) {
+	text := `Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+Upstream-Name: foo
+# A comment
+
+Files: *
+Copyright: 2019 Foo
+ 2020 Bar
+License: Apache-2.0
+ Licensed under the Apache License.
+ .
+ See /usr/share/common-licenses/Apache-2.0.
+
+
+files: src/*.c
+	lib/*
+LICENSE: GPL-2+
+`
+	want := []dep5Paragraph{
+		{Fields: map[string]string{`format`: dep5Format, `upstream-name`: `foo`}, Line: 1},
+		{Fields: map[string]string{
+			`files`:     `*`,
+			`copyright`: "2019 Foo\n2020 Bar",
+			`license`:   "Apache-2.0\nLicensed under the Apache License.\n\nSee /usr/share/common-licenses/Apache-2.0.",
+		}, Line: 5},
+		{Fields: map[string]string{`files`: "src/*.c\nlib/*", `license`: `GPL-2+`}, Line: 14},
+	}
+
+	got, err := parseDep5(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("parseDep5() = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDep5() = %+v, want %+v", got, want)
+	}
+	if got[2].Field(`License`) != `GPL-2+` {
+		t.Errorf("Field(License) = %q, want GPL-2+", got[2].Field(`License`))
+	}
+}
+
+func TestParseDep5Errors(t *testing.T) {
+	tests := []struct {
+		text string
+		err  string
+	}{
+		{" continued\n", "line 1: continuation line outside of a field"},
+		{"Files: *\n\n more\n", "line 3: continuation line outside of a field"},
+		{"Files: *\nno colon here\n", "line 2: expected a field"},
+		{": no name\n", "line 1: expected a field"},
+	}
+
+	for _, test := range tests {
+		if _, err := parseDep5(strings.NewReader(test.text)); err == nil || err.Error() != test.err {
+			t.Errorf("parseDep5(%q) = %v, want %q", test.text, err, test.err)
+		}
+	}
+}
+
+func TestDep5Pattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{`*`, `a/b/c.go`, true},
+		{`src/*`, `src/a/b.c`, true},
+		{`src/*.c`, `src/a/b.c`, true},
+		{`src/*.c`, `src/a/b.h`, false},
+		{`./src/a.c`, `src/a.c`, true},
+		{`src/?.c`, `src/a.c`, true},
+		{`src/?.c`, `src/ab.c`, false},
+		{`src/a+b.c`, `src/a+b.c`, true},
+		{`src/\*.c`, `src/*.c`, true},
+		{`src/\*.c`, `src/a.c`, false},
+		{`src`, `src/a.c`, false},
+	}
+
+	for _, test := range tests {
+		re, err := dep5Pattern(test.pattern)
+		if err != nil {
+			t.Errorf("dep5Pattern(%q) = %v", test.pattern, err)
+			continue
+		}
+		if got := re.MatchString(test.name); got != test.want {
+			t.Errorf("dep5Pattern(%q) matches %q = %v, want %v", test.pattern, test.name, got, test.want)
+		}
+	}
+}
+
+func TestDep5License(t *testing.T) {
+	tests := []struct {
+		field string
+		want  License
+	}{
+		{`Expat`, `MIT`},
+		{`GPL-2+ or Expat`, `GPL-2.0-or-later OR MIT`},
+		{"LGPL-2.1 and BSD-3-clause\n The text.", `LGPL-2.1-only AND BSD-3-Clause`},
+		{`GPL-3+ with OpenSSL-exception`, `GPL-3.0-or-later WITH OpenSSL-exception`},
+		{`LicenseRef-Foo`, `LicenseRef-Foo`},
+		{``, ``},
+	}
+
+	for _, test := range tests {
+		if got := dep5License(test.field); got != test.want {
+			t.Errorf("dep5License(%q) = %q, want %q", test.field, got, test.want)
+		}
+	}
+}
+
+func TestParseDebianCopyright(t *testing.T) {
+	text := `Format: https://www.debian.org/doc/packaging-manuals/copyright-format/1.0/
+
+Files: *
+Copyright: 2019 Foo
+License: Apache-2.0
+
+Files: vendor/* third_party/*
+Copyright: 2018 Bar
+License: Expat
+
+Files: vendor/unknown/*
+Copyright: UNKNOWN
+License: UNKNOWN
+
+Files: vendor/gpl/*
+Copyright: 2017 Baz
+License: GPL-2+
+`
+	rules, err := parseDebianCopyright(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("parseDebianCopyright() = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		lics     []License
+		want     []License
+		line     int
+		conflict string
+	}{
+		{`main.go`, nil, nil, 3, ``},
+		{`main.go`, []License{`Apache-2.0`}, []License{`Apache-2.0`}, 3, ``},
+		{`main.go`, []License{`MIT`}, []License{`MIT`}, 3, `detected MIT, but debian/copyright:3 says Apache-2.0`},
+		{`vendor/a.c`, nil, []License{`MIT`}, 7, ``},
+		{`third_party/a.c`, []License{tooLarge}, []License{`MIT`}, 7, ``},
+		{`vendor/a.c`, []License{`MIT~`}, []License{`MIT~`}, 7, ``},
+		{`vendor/a.c`, []License{`GPL-2.0-only!`}, []License{`GPL-2.0-only!`}, 7, `detected GPL-2.0-only, but debian/copyright:7 says MIT`},
+		{`vendor/unknown/a.c`, nil, nil, 11, ``},
+		{`vendor/unknown/a.c`, []License{`MIT`}, []License{`MIT`}, 11, ``},
+		{`vendor/gpl/a.c`, []License{`Unknown!`}, []License{`Unknown!`}, 15, ``},
+		{`vendor/gpl/a.c`, nil, []License{`GPL-2.0-or-later`}, 15, ``},
+	}
+
+	for _, test := range tests {
+		rule, ok := debianRuleFor(rules, test.name)
+		if !ok || rule.Rule.Line != test.line {
+			t.Errorf("debianRuleFor(%q) = line %d, %v, want line %d", test.name, rule.Rule.Line, ok, test.line)
+		}
+		if got := debianLicenses(rules, test.name, test.lics); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("debianLicenses(%q, %q) = %q, want %q", test.name, test.lics, got, test.want)
+		}
+		if got := debianConflict(rules, test.name, test.lics); got != test.conflict {
+			t.Errorf("debianConflict(%q, %q) = %q, want %q", test.name, test.lics, got, test.conflict)
+		}
+	}
+}
+
+func TestParseDebianCopyrightErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		err  string
+	}{
+		{"Format: " + dep5Format + "\n\nFiles: *\nCopyright: 2019 Foo\n", "line 3: Files paragraph without a License"},
+		{"Format: " + dep5Format + "\n\n continued\n", "continuation line outside of a field"},
+	}
+
+	for _, test := range tests {
+		if _, err := parseDebianCopyright(strings.NewReader(test.text)); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("parseDebianCopyright(%q) = %v, want an error containing %q", test.text, err, test.err)
+		}
+	}
+
+	// A copyright file that isn't machine-readable is ignored.
+	if rules, err := parseDebianCopyright(strings.NewReader("This package was debianized by Foo.\n")); err != nil || rules != nil {
+		t.Errorf("parseDebianCopyright() of a free-form file = %v, %v, want nothing", rules, err)
+	}
+}
+
+func TestMergeYears(t *testing.T) {
+	tests := []struct {
+		years []string
+		want  string
+	}{
+		{nil, ``},
+		{[]string{`2019`}, `2019`},
+		{[]string{`2019`, `2020`}, `2019-2020`},
+		{[]string{`2015, 2017-2019`, `2018-2021`, `2015`}, `2015, 2017-2021`},
+		{[]string{`2010`, `2012`}, `2010, 2012`},
+	}
+
+	for _, test := range tests {
+		if got := mergeYears(test.years); got != test.want {
+			t.Errorf("mergeYears(%q) = %q, want %q", test.years, got, test.want)
+		}
+	}
+}
+
+func TestDep5Expression(t *testing.T) {
+	tests := []struct {
+		lics []License
+		want string
+	}{
+		{nil, dep5Unknown},
+		{[]License{`MIT`}, `MIT`},
+		{[]License{`MIT~!`, `Apache-2.0`}, `Apache-2.0 and MIT`},
+		{[]License{`MIT`, `MIT.header`}, `MIT`},
+		{[]License{`GPL-2.0-or-later OR MIT`, `Zlib`}, `(GPL-2.0-or-later or MIT) and Zlib`},
+		{[]License{`MIT`, `Unknown!`}, dep5Unknown},
+	}
+
+	for _, test := range tests {
+		if got := dep5Expression(test.lics); got != test.want {
+			t.Errorf("dep5Expression(%q) = %q, want %q", test.lics, got, test.want)
+		}
+	}
+}
+
+func TestDep5Escape(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{`src/a.c`, `src/a.c`},
+		{`src/my file.c`, `src/my?file.c`},
+		{`src/*?\.c`, `src/\*\?\\.c`},
+	}
+
+	for _, test := range tests {
+		if got := dep5Escape(test.name); got != test.want {
+			t.Errorf("dep5Escape(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// dep5Paragraph is a paragraph of a Debian machine-readable copyright file
+// (DEP-5), such as a `Files:` paragraph.
+type dep5Paragraph struct {
+	// Fields maps the lowercase name of each field to its value. The lines
+	// of multi-line values are joined with newlines, and a continuation
+	// line of a single `.` is an empty line.
+	Fields map[string]string
+	Line   int // Line the paragraph starts on, starting at 1.
+}
+
+// Field returns the value of a field, ignoring the case of its name.
+func (p dep5Paragraph) Field(name string) string {
+	return p.Fields[strings.ToLower(name)]
+}
+
+// parseDep5 splits a DEP-5 file into its paragraphs.
+func parseDep5(r io.Reader) ([]dep5Paragraph, error) {
+	var paragraphs []dep5Paragraph
+	var current *dep5Paragraph
+	var field string
+
+	s := bufio.NewScanner(r)
+	lineNum := 0
+	for s.Scan() {
+		lineNum++
+		line := s.Text()
+		switch {
+		case strings.TrimSpace(line) == ``:
+			current = nil
+		case strings.HasPrefix(line, `#`):
+		case line[0] == ' ' || line[0] == '\t':
+			if current == nil || field == `` {
+				return nil, fmt.Errorf("line %d: continuation line outside of a field", lineNum)
+			}
+			text := strings.TrimSpace(line)
+			if text == `.` {
+				text = ``
+			}
+			current.Fields[field] += "\n" + text
+		default:
+			i := strings.Index(line, `:`)
+			if i <= 0 {
+				return nil, fmt.Errorf("line %d: expected a field", lineNum)
+			}
+			if current == nil {
+				paragraphs = append(paragraphs, dep5Paragraph{Fields: make(map[string]string), Line: lineNum})
+				current = &paragraphs[len(paragraphs)-1]
+			}
+			field = strings.ToLower(strings.TrimSpace(line[:i]))
+			current.Fields[field] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	return paragraphs, s.Err()
+}
+
+// dep5Pattern converts a pattern of a `Files:` field to a regular expression.
+// In DEP-5, `*` matches any characters, including `/`, `?` matches any single
+// character, and patterns are relative to the root of the project.
+func dep5Pattern(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimPrefix(pattern, `./`)
+	var b strings.Builder
+	b.WriteString(`^`)
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(`.*`)
+		case '?':
+			b.WriteString(`.`)
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString(`$`)
+	return regexp.Compile(b.String())
+}
+
+// dep5Copyrights returns the statements of a `Copyright:` field, one per
+// line.
+func dep5Copyrights(field string) []Copyright {
+	var copyrights []Copyright
+	for _, line := range strings.Split(field, "\n") {
+		if line = strings.TrimSpace(line); line != `` {
+			copyrights = append(copyrights, taggedCopyright(line))
+		}
+	}
+	return copyrights
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// documentCommand proposes LICENSE sections, with `@` lines and full license
+// texts, for every file whose license is not yet documented.
+func documentCommand(args []string) int {
+	flags := flag.NewFlagSet("document", flag.ExitOnError)
+	var write bool
+	flags.BoolVar(&write, "w", false, "Append the sections to the LICENSE file instead of printing them.")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: weasel document [-w]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if _, err := enterProject(`.`); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	if err := loadProject(); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	files, _, err := scan(`.`)
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	// Each project's files are documented in its own LICENSE file.
+	byProject := make(map[*Project]map[string][]License)
+	for name, lics := range files {
+		p := projectFor(name)
+		if byProject[p] == nil {
+			byProject[p] = make(map[string][]License)
+		}
+		byProject[p][p.rel(name)] = lics
+	}
+
+	printed := false
+	for _, p := range sortedProjects() {
+		var sections bytes.Buffer
+		writeSections(&sections, proposeDocumentation(p, byProject[p]))
+		if sections.Len() == 0 {
+			continue
+		}
+
+		if !write {
+			if len(projects) > 1 {
+				if printed {
+					fmt.Println()
+				}
+				fmt.Printf("==> %s <==\n", p.licensePath())
+			}
+			io.Copy(os.Stdout, &sections)
+			printed = true
+			continue
+		}
+
+		if err := appendSections(p.licensePath(), sections.Bytes()); err != nil {
+			fmt.Println(err.Error())
+			return 1
+		}
+	}
+	return 0
+}
+
+// appendSections appends proposed sections to the LICENSE file at licPath.
+func appendSections(licPath string, sections []byte) error {
+	license, err := ioutil.ReadFile(licPath)
+	if err != nil {
+		return fmt.Errorf("Cannot read %s file: %v", licPath, err)
+	}
+	if len(license) != 0 && !bytes.HasSuffix(license, []byte("\n")) {
+		license = append(license, '\n')
+	}
+	license = append(license, '\n')
+	license = append(license, sections...)
+	if err := ioutil.WriteFile(licPath, license, 0644); err != nil {
+		return fmt.Errorf("Cannot write %s file: %v", licPath, err)
+	}
+	return nil
+}
+
+// unknownKey is the documentation key of files whose license could not be
+// determined, which no proposed pattern may cover.
+const unknownKey = `?`
+
+// documentationKey returns the ids of the licenses of a file that need
+// documenting, without any markers or `.header` suffix, joined into a single
+// string. Files whose licenses are all accepted without documentation in
+// project p have an empty key.
+func documentationKey(p *Project, lics []License) string {
+	if len(lics) == 0 {
+		return unknownKey
+	}
+	var ids []string
+	seen := make(map[string]bool)
+	for _, lic := range lics {
+		id := licenseID(lic)
+		if strings.HasPrefix(id, `Unknown`) || strings.HasPrefix(id, `Error: `) {
+			return unknownKey
+		}
+		if !p.accepts(License(strings.TrimSuffix(string(lic), `!`))) && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, `, `)
+}
+
+// proposeDocumentation groups the undocumented files by license, and returns
+// a minimal set of `@` patterns for each group that covers none of the files
+// under another license. The files are those of project p, relative to its
+// directory.
+func proposeDocumentation(p *Project, files map[string][]License) map[string][]string {
+	subtreeKeys := make(map[string]map[string]bool)  // every key found below a directory
+	undocumented := make(map[string]map[string]bool) // keys of undocumented files below a directory
+	direct := make(map[string][]string)              // files directly inside a directory
+	children := make(map[string]map[string]bool)     // directories directly inside a directory
+
+	record := func(m map[string]map[string]bool, dir string, key string) {
+		if m[dir] == nil {
+			m[dir] = make(map[string]bool)
+		}
+		m[dir][key] = true
+	}
+
+	for name, lics := range files {
+		key := documentationKey(p, lics)
+		needsDoc := key != `` && key != unknownKey && strings.HasSuffix(string(lics[0]), `!`)
+
+		dir := path.Dir(name)
+		direct[dir] = append(direct[dir], name)
+		for {
+			record(subtreeKeys, dir, key)
+			if needsDoc {
+				record(undocumented, dir, key)
+			}
+			if dir == `.` {
+				break
+			}
+			parent := path.Dir(dir)
+			record(children, parent, dir)
+			dir = parent
+		}
+	}
+
+	var cover func(dir string, key string) []string
+	cover = func(dir string, key string) []string {
+		if !undocumented[dir][key] {
+			return nil
+		}
+
+		clean := true
+		for k := range subtreeKeys[dir] {
+			if k != key && k != `` {
+				clean = false
+			}
+		}
+		if clean {
+			if dir == `.` {
+				return []string{`**`}
+			}
+			return []string{escapeMatch(dir) + `/**`}
+		}
+
+		var patterns []string
+		sort.Strings(direct[dir])
+		for _, name := range direct[dir] {
+			lics := files[name]
+			if documentationKey(p, lics) == key && strings.HasSuffix(string(lics[0]), `!`) {
+				patterns = append(patterns, escapeMatch(name))
+			}
+		}
+
+		var subdirs []string
+		for child := range children[dir] {
+			subdirs = append(subdirs, child)
+		}
+		sort.Strings(subdirs)
+		for _, child := range subdirs {
+			patterns = append(patterns, cover(child, key)...)
+		}
+		return patterns
+	}
+
+	proposed := make(map[string][]string)
+	for key := range undocumented[`.`] {
+		proposed[key] = cover(`.`, key)
+	}
+	return proposed
+}
+
+// escapeMatch quotes the characters that `@` patterns treat specially.
+func escapeMatch(name string) string {
+	var b strings.Builder
+	for i, c := range name {
+		if strings.ContainsRune(`*?[{}\`, c) || (i == 0 && c == '!') {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// writeSections writes a LICENSE section for each group of proposed patterns,
+// followed by the full text of each license from the license database.
+func writeSections(w io.Writer, proposed map[string][]string) {
+	var keys []string
+	for key := range proposed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for i, key := range keys {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		ids := strings.Split(key, `, `)
+		var names []string
+		for _, id := range ids {
+			if info, ok := licenseInfo(id); ok && info.Name != `` {
+				names = append(names, `the `+info.Name+` (`+id+`)`)
+			} else {
+				names = append(names, `the `+id+` license`)
+			}
+		}
+		fmt.Fprintf(w, "The following files are used under %s:\n", strings.Join(names, ` and `))
+		for _, pattern := range proposed[key] {
+			fmt.Fprintln(w, `@`+pattern)
+		}
+		for _, id := range ids {
+			fmt.Fprintln(w)
+			text, ok := licenseText(id)
+			if !ok {
+				fmt.Fprintf(w, "(The license database has no text for %s. Add it here.)\n", id)
+				continue
+			}
+			fmt.Fprintln(w, strings.TrimRight(text, "\n"))
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDocumentationKey(t *testing.T) {
+	p := &Project{Dir: `.`, Config: Config{License: `Apache-2.0`}}
+	tests := []struct {
+		lics []License
+		want string
+	}{
+		{nil, unknownKey},
+		{[]License{`Apache-2.0`}, ``},
+		{[]License{`Docs`}, ``},
+		{[]License{`MIT!`}, `MIT`},
+		{[]License{`MIT~!`}, `MIT`},
+		{[]License{`MIT.header!`}, `MIT`},
+		{[]License{`MIT.header!`, `MIT!`}, `MIT`},
+		{[]License{`MIT!`, `BSD-3-Clause!`, `Apache-2.0`}, `BSD-3-Clause, MIT`},
+		// An inherited license isn't the project's own, even if its id is.
+		{[]License{`Apache-2.0~!`}, `Apache-2.0`},
+		{[]License{`Unknown!`}, unknownKey},
+		{[]License{`MIT!`, `Error: cannot read!`}, unknownKey},
+	}
+
+	for _, test := range tests {
+		if got := documentationKey(p, test.lics); got != test.want {
+			t.Errorf("documentationKey(%q) = %q, want %q", test.lics, got, test.want)
+		}
+	}
+}
+
+func TestProposeDocumentation(t *testing.T) {
+	p := &Project{Dir: `.`, Config: Config{License: `Apache-2.0`}}
+	files := map[string][]License{
+		`main.go`:                       {`Apache-2.0`},
+		`vendor/foo/a.go`:               {`MIT~!`},
+		`vendor/foo/sub/b.go`:           {`MIT!`},
+		`vendor/bar/a.go`:               {`BSD-3-Clause.header!`},
+		`vendor/bar/LICENSE`:            {`BSD-3-Clause!`},
+		`lib/mixed/x.go`:                {`MIT!`},
+		`lib/mixed/y.go`:                {`Apache-2.0`},
+		`lib/mixed/z.go`:                {`GPL-2.0-only!`},
+		`lib/mixed/deep/w.go`:           {`MIT!`},
+		`lib/unknown/u.go`:              {`Unknown!`},
+		`lib/unknown/m.go`:              {`MIT!`},
+		`third/[weird]/*star.go`:        {`MIT!`},
+		`third/documented/already.go`:   {`MIT`},
+		`third/documented/undocumented`: {`MIT!`},
+	}
+
+	want := map[string][]string{
+		`MIT`: {
+			`lib/mixed/x.go`,
+			`lib/mixed/deep/**`,
+			`lib/unknown/m.go`,
+			`third/**`,
+			`vendor/foo/**`,
+		},
+		`BSD-3-Clause`: {`vendor/bar/**`},
+		`GPL-2.0-only`: {`lib/mixed/z.go`},
+	}
+	if got := proposeDocumentation(p, files); !reflect.DeepEqual(got, want) {
+		t.Errorf("proposeDocumentation() = %q, want %q", got, want)
+	}
+}
+
+func TestEscapeMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{`vendor/foo/a.go`, `vendor/foo/a.go`},
+		{`third/[weird]/*star.go`, `third/\[weird]/\*star.go`},
+		{`a{b,c}?.go`, `a\{b,c\}\?.go`},
+		{`!important`, `\!important`},
+		{`not!first`, `not!first`},
+		{`back\slash`, `back\\slash`},
+	}
+
+	for _, test := range tests {
+		got := escapeMatch(test.name)
+		if got != test.want {
+			t.Errorf("escapeMatch(%q) = %q, want %q", test.name, got, test.want)
+		}
+		if ok, err := globMatch(got, test.name); !ok || err != nil {
+			t.Errorf("pattern %q doesn't match %q: %v", got, test.name, err)
+		}
+	}
+}
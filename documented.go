@@ -20,52 +20,294 @@ package main
 
 import (
 	"bufio"
-	"fmt"
+	"bytes"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"unicode"
 )
 
-type Documented []string
+// Section is a part of the LICENSE file, covering the license text or
+// reference that follows a group of `@` lines.
+type Section struct {
+	Start    int // First line of the section, starting at 1.
+	End      int // Last line of the section.
+	Licenses []License
+}
+
+// DocLine is an `@` line in the LICENSE file.
+type DocLine struct {
+	Pattern string
+	Negate  bool    // The line excludes the files it matches from earlier lines.
+	License License // If set, the line only documents files with this license.
+	Line    int
+	Section *Section
+}
+
+// parseDocLine parses the text of an `@` line following the `@`, which is an
+// optional license and a space, then an optional `!`, then the pattern. The
+// first word is only the license if isLicenseName says it names one;
+// otherwise it starts the pattern, which may contain spaces.
+func parseDocLine(text string) DocLine {
+	var doc DocLine
+	if i := strings.IndexAny(text, " \t"); i >= 0 && isLicenseName(text[:i]) {
+		doc.License = License(text[:i])
+		text = strings.TrimSpace(text[i:])
+	}
+	if strings.HasPrefix(text, `!`) {
+		doc.Negate = true
+		text = text[1:]
+	}
+	doc.Pattern = text
+	return doc
+}
 
+// isLicenseName reports whether word names a license, rather than starting
+// a pattern: it is an id in the license database or of a custom license,
+// possibly with a `+`, or an SPDX LicenseRef- id.
+func isLicenseName(word string) bool {
+	id := strings.TrimSuffix(word, `+`)
+	if _, ok := licenseText(id); ok {
+		return true
+	}
+	if _, ok := licenseInfo(id); ok {
+		return true
+	}
+	return strings.HasPrefix(id, `LicenseRef-`)
+}
+
+// String returns the line as it is written in the LICENSE file, without the
+// leading `@`.
+func (doc DocLine) String() string {
+	s := doc.Pattern
+	if doc.Negate {
+		s = `!` + s
+	}
+	if doc.License != `` {
+		s = string(doc.License) + ` ` + s
+	}
+	return s
+}
+
+// Matches reports whether the line's pattern matches name or, unless the
+// pattern contains `**`, any of its parent directories. A pattern with `**`
+// only matches whole paths, so `dir/**` documents the tree under dir.
+func (doc DocLine) Matches(name string) bool {
+	recursive := strings.Contains(doc.Pattern, `**`)
+	for {
+		if ok, err := globMatch(doc.Pattern, name); ok && err == nil {
+			return true
+		}
+		dir := path.Dir(name)
+		if recursive || dir == `.` || dir == name {
+			return false
+		}
+		name = dir
+	}
+}
+
+type Documented []DocLine
+
+// documented are the `@` lines of the LICENSE file of the project in the
+// working directory.
 var documented Documented
 
-func recordDocumentedLicenses() {
-	f, err := os.Open(`LICENSE`)
+// readLicenseLines reads the lines of the LICENSE file in dir.
+func readLicenseLines(dir string) ([]string, error) {
+	return readLicenseLinesWith(dir, ioutil.ReadFile)
+}
+
+// readLicenseLinesWith reads the lines of the LICENSE file in dir with
+// readFile.
+func readLicenseLinesWith(dir string, readFile func(string) ([]byte, error)) ([]string, error) {
+	b, err := readFile(filepath.Join(dir, `LICENSE`))
 	if err != nil {
-		fmt.Printf("Cannot open LICENSE file: %s!\n", err.Error())
+		return nil, err
 	}
 
-	s := bufio.NewScanner(f)
+	var lines []string
+	s := bufio.NewScanner(bytes.NewReader(b))
 	for s.Scan() {
-		line := strings.TrimSpace(s.Text())
-		if len(line) != 0 && line[0] == '@' {
-			documented = append(documented, line[1:])
+		lines = append(lines, s.Text())
+	}
+	return lines, s.Err()
+}
+
+// ownLicense returns the license of the text of a LICENSE file before its
+// `@` lines, d, if it is a single license.
+func ownLicense(lines []string, d Documented) License {
+	end := len(lines)
+	if len(d) != 0 {
+		end = d[0].Section.Start - 1
+	}
+	if lics := textLicenses(lines[:end]); len(lics) == 1 {
+		return lics[0]
+	}
+	return ``
+}
+
+// textLicenses returns the licenses the classifier finds in text.
+func textLicenses(text []string) []License {
+	var lics []License
+	for _, match := range classifier.MultipleMatch(strings.Join(text, "\n"), false) {
+		if match != nil {
+			lics = append(lics, License(match.Name))
 		}
 	}
+	return Uniq(lics)
 }
 
-func (d Documented) Documents(name string) bool {
-	for _, re := range d {
-		if ok, err := path.Match(re, name); ok && err == nil {
-			return true
+// parseDocumented finds the `@` lines in the lines of the LICENSE file in
+// dir, and splits it into sections. A section starts with the paragraph
+// immediately before a group of `@` lines, and runs until the next such
+// paragraph.
+func parseDocumented(lines []string, dir string) Documented {
+	isDoc := func(i int) bool {
+		line := strings.TrimSpace(lines[i])
+		return len(line) != 0 && line[0] == '@'
+	}
+
+	var starts []int
+	for i := range lines {
+		if isDoc(i) && (i == 0 || !isDoc(i-1)) {
+			start := i
+			for start > 0 && strings.TrimSpace(lines[start-1]) != `` && !isDoc(start-1) {
+				start--
+			}
+			starts = append(starts, start)
+		}
+	}
+
+	var d Documented
+	for n, start := range starts {
+		end := len(lines)
+		if n+1 < len(starts) {
+			end = starts[n+1]
 		}
+
+		section := &Section{Start: start + 1, End: end}
+		var text []string
+		for i := start; i < end; i++ {
+			if isDoc(i) {
+				doc := parseDocLine(strings.TrimSpace(lines[i])[1:])
+				doc.Line = i + 1
+				doc.Section = section
+				d = append(d, doc)
+			} else {
+				text = append(text, lines[i])
+			}
+		}
+		section.Licenses = sectionLicenses(text, dir)
+	}
+	return d
+}
+
+// sectionLicenses determines which licenses a section of the LICENSE file
+// describes. The section's own text is classified first. Failing that, any
+// files it refers to by path are classified, and failing that, the section is
+// searched for the ids of known licenses. Paths are relative to dir, the
+// directory of the LICENSE file.
+func sectionLicenses(text []string, dir string) []License {
+	lics := textLicenses(text)
+	if len(lics) != 0 {
+		return lics
 	}
-	dir := path.Dir(name)
-	if dir != `` && dir != name {
-		return d.Documents(dir)
+
+	for _, line := range text {
+		ref := strings.TrimPrefix(strings.TrimSpace(line), `./`)
+		if ref != `` {
+			ref = filepath.Join(dir, ref)
+		}
+		if fi, err := os.Stat(ref); ref != `` && err == nil && !fi.IsDir() {
+			refLics, _ := fileLicenses(ref)
+			lics = append(lics, refLics...)
+		}
+	}
+	if len(lics) != 0 {
+		return Uniq(lics)
+	}
+
+	for _, line := range text {
+		for _, word := range strings.FieldsFunc(line, func(c rune) bool {
+			return !(unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune(`-.+`, c))
+		}) {
+			word = strings.TrimRight(word, `.`)
+			if _, ok := licenseText(word); ok {
+				lics = append(lics, License(word))
+			}
+		}
+	}
+	return Uniq(lics)
+}
+
+// Documenters returns the `@` lines that document name when it has license
+// lic, or with any license if lic is empty. Lines are applied in order, so a
+// negated line drops the lines before it that matched.
+func (d Documented) Documenters(name string, lic License) []DocLine {
+	var docs []DocLine
+	for _, doc := range d {
+		if lic != `` && doc.License != `` && licenseID(doc.License) != licenseID(lic) {
+			continue
+		}
+		if !doc.Matches(name) {
+			continue
+		}
+		if doc.Negate {
+			docs = nil
+		} else {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// Covers reports whether name is documented for lic, by a line in a section
+// of the LICENSE file for lic. Sections whose license could not be
+// determined, licenses that aren't in the license database, and licenses that
+// need no documentation are not checked against the section.
+func (d Documented) Covers(name string, lic License) bool {
+	docs := d.Documenters(name, lic)
+	if len(docs) == 0 {
+		return false
+	}
+
+	id := licenseID(lic)
+	if _, ok := licenseText(id); !ok || accepted(License(id)) {
+		return true
+	}
+	for _, doc := range docs {
+		if len(doc.Section.Licenses) == 0 {
+			return true
+		}
+		for _, sectionLic := range doc.Section.Licenses {
+			if licenseID(sectionLic) == id {
+				return true
+			}
+		}
 	}
 	return false
 }
 
-func (d Documented) Extra() []string {
-	extra := make(map[string]struct{})
-	for _, s := range d {
-		extra[s] = struct{}{}
+// Extra returns the `@` lines of the LICENSE file in dir that match no file
+// of its project. Of the projects nested in it, only their LICENSE files are
+// part of its project.
+func (d Documented) Extra(dir string) []string {
+	extra := make(map[string]DocLine)
+	for _, doc := range d {
+		extra[doc.String()] = doc
+	}
+	use := func(name string) {
+		name = projectPath(dir, name)
+		for line, doc := range extra {
+			if doc.Matches(name) {
+				delete(extra, line)
+			}
+		}
 	}
 
-	filepath.Walk(`.`, func(name string, info os.FileInfo, err error) error {
+	filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -75,20 +317,22 @@ func (d Documented) Extra() []string {
 		}
 
 		if info.IsDir() {
+			if name != dir && isProjectDir(name) {
+				if _, err := os.Stat(filepath.Join(name, `LICENSE`)); err == nil {
+					use(filepath.Join(name, `LICENSE`))
+				}
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
-		for re := range extra {
-			if ok, err := path.Match(re, name); ok && err == nil {
-				delete(extra, re)
-			}
-		}
+		use(name)
 		return nil
 	})
 
 	var extraDoc []string
-	for re := range extra {
-		extraDoc = append(extraDoc, re)
+	for line := range extra {
+		extraDoc = append(extraDoc, line)
 	}
 	return extraDoc
 }
@@ -0,0 +1,126 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"testing"
+)
+
+func TestParseDocLine(t *testing.T) {
+	tests := []struct {
+		text string
+		want DocLine
+	}{
+		{`vendor/**`, DocLine{Pattern: `vendor/**`}},
+		{`!vendor/testdata/**`, DocLine{Pattern: `vendor/testdata/**`, Negate: true}},
+		{`MIT vendor/**`, DocLine{Pattern: `vendor/**`, License: `MIT`}},
+		{`MIT !vendor/**`, DocLine{Pattern: `vendor/**`, License: `MIT`, Negate: true}},
+		{`GPL-2.0+ lib/**`, DocLine{Pattern: `lib/**`, License: `GPL-2.0+`}},
+		{`LicenseRef-Internal lib/**`, DocLine{Pattern: `lib/**`, License: `LicenseRef-Internal`}},
+		{`vendor/My Lib/**`, DocLine{Pattern: `vendor/My Lib/**`}},
+		{`My Lib/**`, DocLine{Pattern: `My Lib/**`}},
+	}
+
+	for _, test := range tests {
+		if got := parseDocLine(test.text); got != test.want {
+			t.Errorf("parseDocLine(%q) = %+v, want %+v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestIsLicenseName(t *testing.T) {
+	overrideFilters = []overrideFilter{{License: `Proprietary`}}
+	defer func() { overrideFilters = nil }()
+
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{`MIT`, true},
+		{`GPL-2.0+`, true},
+		{`LicenseRef-Internal`, true},
+		// A license given by an override isn't known unless it's a custom
+		// license, so it starts a pattern.
+		{`Proprietary`, false},
+		{`vendor/My`, false},
+		{`My`, false},
+	}
+
+	for _, test := range tests {
+		if got := isLicenseName(test.word); got != test.want {
+			t.Errorf("isLicenseName(%q) = %v, want %v", test.word, got, test.want)
+		}
+	}
+}
+
+func TestDocumenters(t *testing.T) {
+	var d Documented
+	for i, text := range []string{
+		`vendor/**`,
+		`!vendor/foo/testdata/**`,
+		`vendor/foo/testdata/keep.go`,
+		`MIT third/**`,
+		`docs`,
+		`third_party/foo/*`,
+		`lib/*.go`,
+	} {
+		doc := parseDocLine(text)
+		doc.Line = i + 1
+		doc.Section = &Section{}
+		d = append(d, doc)
+	}
+
+	tests := []struct {
+		name string
+		lic  License
+		want []int // The lines documenting name.
+	}{
+		{`vendor/foo/a.go`, ``, []int{1}},
+		{`vendor/foo/testdata/a.go`, ``, nil},
+		{`vendor/foo/testdata/keep.go`, ``, []int{3}},
+		{`third/a.go`, `MIT`, []int{4}},
+		{`third/a.go`, `MIT~`, []int{4}},
+		{`third/a.go`, `BSD-3-Clause`, nil},
+		{`third/a.go`, ``, []int{4}},
+		{`docs`, ``, []int{5}},
+		{`docs/a.md`, ``, []int{5}},
+		{`third_party/foo/sub/x.c`, ``, []int{6}},
+		{`third_party/foo`, ``, nil},
+		{`lib/a.go/b.c`, ``, []int{7}},
+		{`vendorx/a.go`, ``, nil},
+		{`other.go`, ``, nil},
+	}
+
+	for _, test := range tests {
+		var got []int
+		for _, doc := range d.Documenters(test.name, test.lic) {
+			got = append(got, doc.Line)
+		}
+		if len(got) != len(test.want) {
+			t.Errorf("Documenters(%q, %q) = lines %v, want %v", test.name, test.lic, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("Documenters(%q, %q) = lines %v, want %v", test.name, test.lic, got, test.want)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// explainCommand prints the full decision trail weasel follows for a single
+// file, from ignore rules through to the final verdict.
+func explainCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: weasel explain <path>")
+		return 1
+	}
+
+	name, err := enterProject(args[0])
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	if err := loadProject(); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	explain(os.Stdout, name)
+	return 0
+}
+
+// enterProject finds the project root containing target, changes into it,
+// and returns target relative to the root.
+func enterProject(target string) (string, error) {
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return ``, fmt.Errorf("Unable to get absolute path for %s: %v", target, err)
+	}
+
+	root, err := findRoot()
+	if err != nil {
+		return ``, fmt.Errorf("Unable to get working directory: %v", err)
+	}
+	if root == `` {
+		root, err = os.Getwd()
+		if err != nil {
+			return ``, fmt.Errorf("Unable to get working directory: %v", err)
+		}
+	}
+
+	name, err := filepath.Rel(root, abs)
+	if err != nil || name == `..` || strings.HasPrefix(name, `../`) {
+		return ``, fmt.Errorf("%s is not inside the project at %s!", target, root)
+	}
+
+	if err := os.Chdir(root); err != nil {
+		return ``, fmt.Errorf("Failed to enter target directory: %v!", err)
+	}
+	initGit()
+	return name, nil
+}
+
+func explain(w io.Writer, name string) {
+	step := func(label string, format string, a ...interface{}) {
+		fmt.Fprintf(w, "%-12s %s\n", label+":", fmt.Sprintf(format, a...))
+	}
+
+	step("File", "%s", name)
+
+	for _, part := range strings.Split(name, `/`) {
+		if part == `.git` {
+			step("Decision", "skipped, files inside .git are never checked")
+			return
+		}
+	}
+
+	if rule := IgnoreRule(name); rule != `` {
+		step("Ignored", "yes, by %s", rule)
+		step("Decision", "skipped, ignored files are never checked")
+		return
+	}
+	step("Ignored", "no")
+
+	info, err := os.Lstat(name)
+	if err != nil {
+		step("Decision", "error, %v", err)
+		return
+	}
+	if info.IsDir() {
+		step("Decision", "skipped, %s is a directory", name)
+		return
+	}
+	if (info.Mode() & os.ModeSymlink) != 0 {
+		step("Decision", "skipped, symbolic links are never checked")
+		return
+	}
+
+	source, annotation := reuseSource(name), reuseAnnotationFor(name)
+	if source != name {
+		step("REUSE", "licenses and copyrights are read from the sidecar %s", source)
+	}
+	if annotation != nil {
+		step("REUSE", "%s covers this file, with %s precedence: %s", annotation.Source, annotation.Precedence, listLicenses(annotation.Licenses))
+	}
+
+	switch {
+	case annotation.overrides():
+		step("Content", "not read, the REUSE annotation overrides it")
+	case info.Size() == 0 && source == name:
+		step("Content", "empty file")
+	default:
+		sourceInfo, err := os.Stat(source)
+		if err != nil {
+			step("Content", "error, %v", err)
+		} else {
+			explainContent(step, source, sourceInfo)
+		}
+	}
+
+	for _, rule := range overrideRules[name] {
+		action := "applies"
+		if strings.HasPrefix(string(rule.License), `!`) {
+			action = "negates"
+		}
+		step("Override", "%s:%d %s %s (%s)", rule.Source, rule.Line, action, strings.TrimPrefix(string(rule.License), `!`), rule.Text)
+	}
+
+	if rule, ok := debianRuleFor(debianRules, name); ok {
+		step("Debian", "%s:%d says %s (%s)", rule.Rule.Source, rule.Rule.Line, rule.Rule.License, rule.Rule.Text)
+	}
+
+	lics := classify(name, info)
+	step("Detected", "%s", listLicenses(lics))
+	if conflict := debianConflict(debianRules, name, lics); conflict != `` {
+		step("Conflict", "%s", conflict)
+	}
+	for _, lic := range lics {
+		if info, ok := licenseInfo(licenseID(lic)); ok {
+			step("License", "%s is the %s", licenseID(lic), info.describe())
+		}
+	}
+
+	// The licenses are resolved by resolveFile, as in a scan, and the trail
+	// shows the LICENSE file and the kind it used.
+	var licPath, kind string
+	resolved := resolveFile(name, lics, func(path string) []License {
+		fi, err := os.Stat(path)
+		if err != nil || fi.IsDir() || Ignored(path) {
+			return nil
+		}
+		found := classify(path, fi)
+		if len(found) != 0 {
+			licPath = path
+		}
+		return found
+	}, func(name string) string {
+		kind = filekind(name)
+		return kind
+	})
+
+	if licPath != `` {
+		lics = nil
+		for _, lic := range resolved {
+			lics = append(lics, License(strings.TrimSuffix(string(lic), `!`)))
+		}
+		step("Inherited", "%s from %s", listLicenses(lics), licPath)
+	} else if len(lics) == 0 || isTooLarge(lics) {
+		step("Inherited", "none, no licensed LICENSE or COPYING file above this file")
+	}
+
+	switch {
+	case kind != ``:
+		step("File kind", "%s", kind)
+	case len(lics) != 0 && !isTooLarge(lics):
+		p := projectFor(name)
+		if p.Dir != `.` {
+			step("Project", "%s, under %s", p.Dir, p.Config.License)
+		}
+		licensePath := p.licensePath()
+		for _, doc := range p.Documented {
+			if doc.Negate && doc.Matches(p.rel(name)) {
+				step("Excluded", "by %s:%d @%s", licensePath, doc.Line, doc)
+			}
+		}
+		docs := p.Documented.Documenters(p.rel(name), ``)
+		for _, doc := range docs {
+			step("Documented", "by %s:%d @%s, in the section at lines %d-%d for %s", licensePath, doc.Line, doc, doc.Section.Start, doc.Section.End, listLicenses(doc.Section.Licenses))
+		}
+		if len(docs) == 0 && !p.needsDocumentation(lics) {
+			step("Documented", "not required for %s", listLicenses(lics))
+		}
+		for _, lic := range lics {
+			if !p.accepts(lic) && !p.Documented.Covers(p.rel(name), lic) {
+				step("Documented", "no, no @ line in a %s section for %s covers this file", licensePath, lic)
+			}
+		}
+	}
+
+	licStr, ignore, undoc := verdict(resolved)
+	switch {
+	case ignore:
+		step("Decision", "ignored by override (%s)", licStr)
+	case undoc:
+		step("Decision", "Error, %s", licStr)
+	default:
+		step("Decision", "OK, %s", licStr)
+	}
+}
+
+// explainContent describes how the contents of a non-empty file were
+// classified.
+func explainContent(step func(string, string, ...interface{}), name string, info os.FileInfo) {
+	head, tail, err := spdxWindows(name)
+	if err != nil {
+		step("SPDX", "error, %v", err)
+		return
+	}
+
+	var tags int
+	for _, window := range []struct {
+		Name string
+		B    []byte
+	}{{"head", head}, {"tail", tail}} {
+		for _, tag := range spdxTags(window.B) {
+			step("SPDX", "%s line %d: %s", window.Name, tag.Line, tag.Text)
+			tags++
+		}
+	}
+	if tags != 0 {
+		step("Classifier", "not run, explicit SPDX identifiers take precedence")
+		return
+	}
+	step("SPDX", "no SPDX-License-Identifier lines found")
+
+	if large := projectFor(name).Config.Large; info.Size() > large.Limit {
+		if large.Mode == `skip` {
+			step("Classifier", "not run, file is larger than %d bytes, so %s", large.Limit, tooLarge)
+			return
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			step("Classifier", "error, %v", err)
+			return
+		}
+		defer f.Close()
+		matches, err := largeMatches(name, f, info.Size())
+		if err != nil {
+			step("Classifier", "error, %v", err)
+			return
+		}
+		step("Classifier", "file is larger than %d bytes, so only %d chunks of %d bytes were classified (%s mode)", large.Limit, len(large.chunks(info.Size())), large.Window, large.Mode)
+		for _, m := range matches {
+			step("Classifier", "%s (confidence %.2f)", m.License, m.Confidence)
+		}
+		return
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		step("Classifier", "error, %v", err)
+		return
+	}
+	defer f.Close()
+
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		step("Classifier", "error, %v", err)
+		return
+	}
+	text := string(b)
+
+	matches := classifier.MultipleMatch(text, true)
+	if len(matches) == 0 {
+		step("Classifier", "no matches")
+		if !isLicenseFile(name) {
+			return
+		}
+		v, confidence := nearestVariant(text)
+		if v == nil {
+			step("Variant", "no license is near enough to compare")
+			return
+		}
+		step("Variant", "nearest match %s (confidence %.2f)", v.License, confidence)
+		explainVariant(step, v)
+	}
+	for _, match := range matches {
+		if match == nil {
+			continue
+		}
+		step("Classifier", "%s (confidence %.2f)", match.Name, match.Confidence)
+		if match.Confidence >= 1 || !isLicenseFile(name) {
+			continue
+		}
+		if v := findVariant(match.Name, text); v != nil {
+			explainVariant(step, v)
+		}
+	}
+}
+
+// explainVariant shows the substantive changes a file makes to a license.
+func explainVariant(step func(string, string, ...interface{}), v *Variant) {
+	id := strings.TrimSuffix(v.License, `.header`)
+	if len(v.Changes) == 0 {
+		step("Variant", "none, differences from %s are outside the license or in replaceable text", v.License)
+		return
+	}
+	step("Variant", "%s, %d substantive change(s) to %s", id+variantSuffix, len(v.Changes), v.License)
+	for _, c := range v.Changes {
+		step("Diff", "%s", c)
+	}
+}
+
+func listLicenses(lics []License) string {
+	if len(lics) == 0 {
+		return "none"
+	}
+	strs := make([]string, len(lics))
+	for i, lic := range lics {
+		strs[i] = string(lic)
+	}
+	return strings.Join(strs, `, `)
+}
@@ -21,9 +21,11 @@ package main
 import (
 	"bytes"
 	"os/exec"
+	"time"
 )
 
 func filekind(name string) string {
+	defer spent(&timings.File, time.Now())
 	b, err := exec.Command(`file`, `-b`, name).CombinedOutput()
 	if err != nil {
 		return ``
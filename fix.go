@@ -0,0 +1,376 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// commentStyle describes how to write a comment in a kind of file. Block
+// styles set Start and End; line styles set Line, which prefixes every line.
+type commentStyle struct {
+	Start string
+	Line  string
+	End   string
+}
+
+var (
+	blockComment = commentStyle{Start: `/*`, End: `*/`}
+	hashComment  = commentStyle{Line: `#`}
+	dashComment  = commentStyle{Line: `--`}
+	xmlComment   = commentStyle{Start: `<!--`, End: `-->`}
+)
+
+var extensionStyles = map[string]commentStyle{
+	`.c`: blockComment, `.h`: blockComment, `.cc`: blockComment, `.cpp`: blockComment, `.hpp`: blockComment,
+	`.go`: blockComment, `.java`: blockComment, `.js`: blockComment, `.jsx`: blockComment, `.ts`: blockComment,
+	`.tsx`: blockComment, `.css`: blockComment, `.scss`: blockComment, `.less`: blockComment, `.rs`: blockComment,
+	`.swift`: blockComment, `.kt`: blockComment, `.scala`: blockComment, `.groovy`: blockComment, `.php`: blockComment,
+	`.proto`: blockComment,
+
+	`.sh`: hashComment, `.bash`: hashComment, `.zsh`: hashComment, `.py`: hashComment, `.rb`: hashComment,
+	`.pl`: hashComment, `.yml`: hashComment, `.yaml`: hashComment, `.toml`: hashComment, `.conf`: hashComment,
+	`.cfg`: hashComment, `.mk`: hashComment, `.cmake`: hashComment, `.r`: hashComment, `.properties`: hashComment,
+	`.tf`: hashComment, `.ps1`: hashComment,
+
+	`.sql`: dashComment, `.lua`: dashComment, `.hs`: dashComment,
+
+	`.html`: xmlComment, `.htm`: xmlComment, `.xml`: xmlComment, `.xsd`: xmlComment, `.xsl`: xmlComment,
+	`.svg`: xmlComment, `.md`: xmlComment, `.vue`: xmlComment,
+}
+
+var nameStyles = map[string]commentStyle{
+	`Dockerfile`: hashComment, `Makefile`: hashComment, `Gemfile`: hashComment, `Rakefile`: hashComment,
+	`Vagrantfile`: hashComment, `CMakeLists.txt`: hashComment, `Jenkinsfile`: blockComment,
+	`.gitignore`: hashComment, `.gitattributes`: hashComment, `.dockerignore`: hashComment,
+	`.dependency_license`: hashComment,
+}
+
+// styleFor chooses the comment style for a file by its name, falling back to
+// `#` comments for scripts that start with a shebang.
+func styleFor(name string, content []byte) (commentStyle, bool) {
+	base := filepath.Base(name)
+	if style, ok := nameStyles[base]; ok {
+		return style, true
+	}
+	if strings.HasPrefix(base, `Dockerfile`) {
+		return hashComment, true
+	}
+	if style, ok := extensionStyles[strings.ToLower(filepath.Ext(base))]; ok {
+		return style, true
+	}
+	if bytes.HasPrefix(content, []byte(`#!`)) {
+		return hashComment, true
+	}
+	return commentStyle{}, false
+}
+
+const apacheBoilerplate = `Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.`
+
+const asfBoilerplate = `Licensed to the Apache Software Foundation (ASF) under one
+or more contributor license agreements.  See the NOTICE file
+distributed with this work for additional information
+regarding copyright ownership.  The ASF licenses this file
+to you under the Apache License, Version 2.0 (the
+"License"); you may not use this file except in compliance
+with the License.  You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing,
+software distributed under the License is distributed on an
+"AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations
+under the License.`
+
+// headerText returns the uncommented lines of the configured license header
+// of project p.
+func headerText(p *Project) ([]string, error) {
+	c := p.Config
+	var text string
+	switch c.Header {
+	case `apache`:
+		text = apacheBoilerplate + "\n\nSPDX-License-Identifier: Apache-2.0"
+	case `asf`:
+		text = asfBoilerplate + "\n\nSPDX-License-Identifier: Apache-2.0"
+	case `spdx`:
+		text = `SPDX-License-Identifier: ` + c.License
+	default:
+		return nil, fmt.Errorf("Unknown header %q in %s, expected apache, asf or spdx", c.Header, filepath.Join(p.Dir, configFile))
+	}
+	if c.Holder != `` {
+		sep := "\n\n"
+		if c.Header == `spdx` {
+			sep = "\n"
+		}
+		text = `Copyright ` + strconv.Itoa(time.Now().Year()) + ` ` + c.Holder + sep + text
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+// comment renders header in style.
+func (style commentStyle) comment(header []string) []string {
+	var lines []string
+	if style.Start != `` {
+		lines = append(lines, style.Start)
+		lines = append(lines, header...)
+		return append(lines, style.End)
+	}
+	for _, line := range header {
+		lines = append(lines, strings.TrimRight(style.Line+` `+line, ` `))
+	}
+	return lines
+}
+
+// preamble returns the number of leading lines that must stay ahead of an
+// inserted header: shebangs, encoding and parser directives, XML
+// declarations, and Go build constraints or generated code markers.
+func preamble(name string, style commentStyle, lines []string) int {
+	keep := 0
+	line := func(i int) string {
+		if i >= len(lines) {
+			return ``
+		}
+		return strings.TrimRight(lines[i], "\r\n")
+	}
+
+	if strings.HasPrefix(line(keep), `#!`) {
+		keep++
+	}
+
+	switch {
+	case style == hashComment:
+		for keep < len(lines) {
+			l := strings.ToLower(line(keep))
+			if !(strings.HasPrefix(l, `#`) && (strings.Contains(l, `coding:`) || strings.Contains(l, `coding=`) || strings.HasPrefix(l, `# syntax=`) || strings.HasPrefix(l, `# escape=`))) {
+				break
+			}
+			keep++
+		}
+	case style == xmlComment:
+		if strings.HasPrefix(line(keep), `<?xml`) {
+			keep++
+		}
+		if strings.HasPrefix(strings.ToUpper(line(keep)), `<!DOCTYPE`) {
+			keep++
+		}
+	case filepath.Ext(name) == `.php`:
+		if strings.HasPrefix(line(keep), `<?php`) {
+			keep++
+		}
+	case filepath.Ext(name) == `.go`:
+		last := -1
+		for i := keep; i < len(lines); i++ {
+			l := line(i)
+			if l != `` && !strings.HasPrefix(l, `//`) {
+				break
+			}
+			if strings.HasPrefix(l, `//go:build`) || strings.HasPrefix(l, `// +build`) || strings.HasPrefix(l, `// Code generated`) {
+				last = i
+			}
+		}
+		if last >= 0 {
+			keep = last + 1
+			if keep < len(lines) && line(keep) == `` {
+				keep++
+			}
+		}
+	}
+	return keep
+}
+
+// insertHeader returns the lines of content with header inserted, along with
+// the index at which it was inserted and the inserted lines.
+func insertHeader(name string, style commentStyle, content string, header []string) ([]string, int, []string) {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == `` {
+		lines = lines[:len(lines)-1]
+	}
+	eol := "\n"
+	if strings.Contains(content, "\r\n") {
+		eol = "\r\n"
+	}
+
+	at := preamble(name, style, lines)
+	if at > 0 && !strings.HasSuffix(lines[at-1], "\n") {
+		lines[at-1] += eol
+	}
+
+	var inserted []string
+	if at > 0 && strings.TrimSpace(lines[at-1]) != `` {
+		inserted = append(inserted, eol)
+	}
+	for _, l := range style.comment(header) {
+		inserted = append(inserted, l+eol)
+	}
+	if at < len(lines) && strings.TrimSpace(lines[at]) != `` {
+		inserted = append(inserted, eol)
+	}
+
+	result := append([]string{}, lines[:at]...)
+	result = append(result, inserted...)
+	result = append(result, lines[at:]...)
+	return result, at, inserted
+}
+
+// writeInsertionDiff writes a unified diff of inserting lines at index at of
+// the original file.
+func writeInsertionDiff(w io.Writer, name string, original []string, at int, inserted []string) {
+	const context = 3
+	before := at - context
+	if before < 0 {
+		before = 0
+	}
+	after := at + context
+	if after > len(original) {
+		after = len(original)
+	}
+
+	oldCount := after - before
+	oldStart := before + 1
+	if oldCount == 0 {
+		oldStart = 0
+	}
+	fmt.Fprintf(w, "--- a/%s\n+++ b/%s\n", name, name)
+	fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, before+1, oldCount+len(inserted))
+	for _, l := range original[before:at] {
+		fmt.Fprint(w, ` `+strings.TrimRight(l, "\r\n")+"\n")
+	}
+	for _, l := range inserted {
+		fmt.Fprint(w, `+`+strings.TrimRight(l, "\r\n")+"\n")
+	}
+	for _, l := range original[at:after] {
+		fmt.Fprint(w, ` `+strings.TrimRight(l, "\r\n")+"\n")
+	}
+}
+
+// isBinary guesses whether content is binary by looking for a NUL byte near
+// the start, as git and diff do.
+func isBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// fixCommand inserts the license header of each file's project into files
+// that have no detected license. Without -w it only prints the changes it would make.
+func fixCommand(args []string) int {
+	flags := flag.NewFlagSet("fix", flag.ExitOnError)
+	var write bool
+	flags.BoolVar(&write, "w", false, "Write the headers to the files instead of printing a diff.")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: weasel fix [-w] [dir]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	target := `.`
+	if flags.NArg() > 0 {
+		target = flags.Arg(0)
+	}
+	subdir, err := enterProject(target)
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	if err := loadProject(); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	headers := make(map[*Project][]string)
+	for _, p := range sortedProjects() {
+		header, err := headerText(p)
+		if err != nil {
+			fmt.Println(err.Error())
+			return 1
+		}
+		headers[p] = header
+	}
+
+	files, _, err := scan(subdir)
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	var filenames []string
+	for name, lics := range files {
+		if len(lics) == 0 || (len(lics) == 1 && strings.HasPrefix(string(lics[0]), `Unknown`)) {
+			filenames = append(filenames, name)
+		}
+	}
+	sort.Strings(filenames)
+
+	code := 0
+	for _, name := range filenames {
+		content, err := ioutil.ReadFile(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to read %s: %v\n", name, err)
+			code = 1
+			continue
+		}
+		if isBinary(content) {
+			fmt.Fprintf(os.Stderr, "Skipping %s: binary file\n", name)
+			continue
+		}
+		style, ok := styleFor(name, content)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Skipping %s: unknown comment syntax\n", name)
+			continue
+		}
+
+		lines, at, inserted := insertHeader(name, style, string(content), headers[projectFor(name)])
+		original := append(append([]string{}, lines[:at]...), lines[at+len(inserted):]...)
+		writeInsertionDiff(os.Stdout, name, original, at, inserted)
+
+		if write {
+			info, err := os.Stat(name)
+			if err == nil {
+				err = ioutil.WriteFile(name, []byte(strings.Join(lines, ``)), info.Mode())
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Unable to write %s: %v\n", name, err)
+				code = 1
+			}
+		}
+	}
+	return code
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStyleFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    commentStyle
+		ok      bool
+	}{
+		{`main.go`, ``, blockComment, true},
+		{`lib/Util.JAVA`, ``, blockComment, true},
+		{`setup.py`, ``, hashComment, true},
+		{`schema.sql`, ``, dashComment, true},
+		{`index.html`, ``, xmlComment, true},
+		{`Makefile`, ``, hashComment, true},
+		{`Dockerfile.dev`, ``, hashComment, true},
+		{`Jenkinsfile`, ``, blockComment, true},
+		{`bin/run`, "#!/bin/sh\necho hi\n", hashComment, true},
+		{`bin/run`, "echo hi\n", commentStyle{}, false},
+		{`data.bin`, ``, commentStyle{}, false},
+	}
+
+	for _, test := range tests {
+		got, ok := styleFor(test.name, []byte(test.content))
+		if got != test.want || ok != test.ok {
+			t.Errorf("styleFor(%q) = %+v, %v, want %+v, %v", test.name, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestComment(t *testing.T) {
+	header := []string{`Copyright 2026 Foo`, ``, `SPDX-License-Identifier: MIT`}
+	tests := []struct {
+		style commentStyle
+		want  []string
+	}{
+		{blockComment, []string{`/*`, `Copyright 2026 Foo`, ``, `SPDX-License-Identifier: MIT`, `*/`}},
+		{xmlComment, []string{`<!--`, `Copyright 2026 Foo`, ``, `SPDX-License-Identifier: MIT`, `-->`}},
+		{hashComment, []string{`# Copyright 2026 Foo`, `#`, `# SPDX-License-Identifier: MIT`}},
+		{dashComment, []string{`-- Copyright 2026 Foo`, `--`, `-- SPDX-License-Identifier: MIT`}},
+	}
+
+	for _, test := range tests {
+		if got := test.style.comment(header); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%+v.comment() = %q, want %q", test.style, got, test.want)
+		}
+	}
+}
+
+func TestInsertHeader(t *testing.T) {
+	header := []string{`SPDX-License-Identifier: MIT`}
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    `main.go`,
+			content: "package main\n",
+			want:    "/*\nSPDX-License-Identifier: MIT\n*/\n\npackage main\n",
+		},
+		{
+			name:    `main.go`,
+			content: "// Package main runs.\npackage main\n",
+			want:    "/*\nSPDX-License-Identifier: MIT\n*/\n\n// Package main runs.\npackage main\n",
+		},
+		{
+			name:    `linux.go`,
+			content: "//go:build linux\n// +build linux\n\npackage main\n",
+			want:    "//go:build linux\n// +build linux\n\n/*\nSPDX-License-Identifier: MIT\n*/\n\npackage main\n",
+		},
+		{
+			name:    `gen.go`,
+			content: "// Code generated by stringer. DO NOT EDIT.\npackage main\n",
+			want:    "// Code generated by stringer. DO NOT EDIT.\n\n/*\nSPDX-License-Identifier: MIT\n*/\n\npackage main\n",
+		},
+		{
+			name:    `run.sh`,
+			content: "#!/bin/sh\necho hi\n",
+			want:    "#!/bin/sh\n\n# SPDX-License-Identifier: MIT\n\necho hi\n",
+		},
+		{
+			name:    `run.sh`,
+			content: "#!/bin/sh",
+			want:    "#!/bin/sh\n\n# SPDX-License-Identifier: MIT\n",
+		},
+		{
+			name:    `tool.py`,
+			content: "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\nimport os\n",
+			want:    "#!/usr/bin/env python\n# -*- coding: utf-8 -*-\n\n# SPDX-License-Identifier: MIT\n\nimport os\n",
+		},
+		{
+			name:    `Dockerfile`,
+			content: "# syntax=docker/dockerfile:1\nFROM scratch\n",
+			want:    "# syntax=docker/dockerfile:1\n\n# SPDX-License-Identifier: MIT\n\nFROM scratch\n",
+		},
+		{
+			name:    `pom.xml`,
+			content: "<?xml version=\"1.0\"?>\n<!DOCTYPE project>\n<project/>\n",
+			want:    "<?xml version=\"1.0\"?>\n<!DOCTYPE project>\n\n<!--\nSPDX-License-Identifier: MIT\n-->\n\n<project/>\n",
+		},
+		{
+			name:    `index.php`,
+			content: "<?php\necho 1;\n",
+			want:    "<?php\n\n/*\nSPDX-License-Identifier: MIT\n*/\n\necho 1;\n",
+		},
+		{
+			name:    `schema.sql`,
+			content: "\nselect 1;\n",
+			want:    "-- SPDX-License-Identifier: MIT\n\nselect 1;\n",
+		},
+		{
+			name:    `main.go`,
+			content: "package main\r\n",
+			want:    "/*\r\nSPDX-License-Identifier: MIT\r\n*/\r\n\r\npackage main\r\n",
+		},
+		{
+			name:    `empty.sh`,
+			content: ``,
+			want:    "# SPDX-License-Identifier: MIT\n",
+		},
+	}
+
+	for _, test := range tests {
+		style, _ := styleFor(test.name, []byte(test.content))
+		lines, at, inserted := insertHeader(test.name, style, test.content, header)
+		if got := strings.Join(lines, ``); got != test.want {
+			t.Errorf("insertHeader(%q, %q) = %q, want %q", test.name, test.content, got, test.want)
+			continue
+		}
+		if got := strings.Join(lines[at:at+len(inserted)], ``); got != strings.Join(inserted, ``) {
+			t.Errorf("insertHeader(%q, %q) inserted %q at %d, but the lines there are %q", test.name, test.content, inserted, at, got)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// globMatch reports whether name matches pattern. Patterns use the syntax of
+// path.Match within each path segment, and additionally support `**` as a
+// whole segment, matching zero or more segments, and `{a,b}` alternatives.
+func globMatch(pattern string, name string) (bool, error) {
+	for _, alt := range expandBraces(pattern) {
+		ok, err := matchSegments(strings.Split(alt, `/`), strings.Split(name, `/`))
+		if ok || err != nil {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func matchSegments(pattern []string, name []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == `**` {
+			for len(pattern) > 1 && pattern[1] == `**` {
+				pattern = pattern[1:]
+			}
+			for i := 0; i <= len(name); i++ {
+				if ok, err := matchSegments(pattern[1:], name[i:]); ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(name) == 0 {
+			return false, nil
+		}
+		ok, err := path.Match(collapseStars(pattern[0]), name[0])
+		if !ok || err != nil {
+			return false, err
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0, nil
+}
+
+// collapseStars replaces each run of unescaped `*` in a segment of a pattern
+// with a single `*`, which path.Match treats the same within a segment.
+func collapseStars(segment string) string {
+	var b strings.Builder
+	for i := 0; i < len(segment); i++ {
+		b.WriteByte(segment[i])
+		switch {
+		case segment[i] == '\\' && i+1 < len(segment):
+			i++
+			b.WriteByte(segment[i])
+		case segment[i] == '*':
+			for i+1 < len(segment) && segment[i+1] == '*' {
+				i++
+			}
+		}
+	}
+	return b.String()
+}
+
+// expandBraces expands the first unescaped `{a,b}` group in pattern into each
+// of its alternatives, recursively. Patterns with unbalanced braces are
+// returned as they are.
+func expandBraces(pattern string) []string {
+	start, depth := -1, 0
+	var commas []int
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '{':
+			if depth == 0 {
+				start = i
+				commas = nil
+			}
+			depth++
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		case '}':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				prefix, suffix := pattern[:start], pattern[i+1:]
+				var expanded []string
+				bounds := append(append([]int{start}, commas...), i)
+				for j := 0; j+1 < len(bounds); j++ {
+					alt := prefix + pattern[bounds[j]+1:bounds[j+1]] + suffix
+					expanded = append(expanded, expandBraces(alt)...)
+				}
+				return expanded
+			}
+		}
+	}
+	return []string{pattern}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{`a/b.go`, `a/b.go`, true},
+		{`a/b.go`, `a/b.go/c`, false},
+		{`a`, `a/b.go`, false},
+		{`*.go`, `b.go`, true},
+		{`*.go`, `a/b.go`, false},
+		{`a/*`, `a/b.go`, true},
+		{`a/*`, `a/b/c.go`, false},
+		{`a/?.go`, `a/b.go`, true},
+		{`a/?.go`, `a/bc.go`, false},
+		{`a/[bc].go`, `a/c.go`, true},
+		{`a/[^bc].go`, `a/c.go`, false},
+
+		// `**` as a whole segment matches zero or more segments.
+		{`**`, `a/b/c.go`, true},
+		{`a/**`, `a/b/c.go`, true},
+		{`a/**`, `a`, true},
+		{`a/**`, `ab/c.go`, false},
+		{`**/c.go`, `c.go`, true},
+		{`**/c.go`, `a/b/c.go`, true},
+		{`a/**/c.go`, `a/c.go`, true},
+		{`a/**/c.go`, `a/b/d/c.go`, true},
+		{`a/**/**/c.go`, `a/b/c.go`, true},
+		{`a/**/c.go`, `a/b/d.go`, false},
+		// Within a segment, `**` is the same as `*`.
+		{`a/b**.go`, `a/bc.go`, true},
+		{`a/b**.go`, `a/b/c.go`, false},
+
+		// Braces match any of their alternatives.
+		{`a/*.{go,js}`, `a/b.js`, true},
+		{`a/*.{go,js}`, `a/b.py`, false},
+		{`{a,b}/**`, `b/c/d.go`, true},
+		{`{a,b/{c,d}}/e.go`, `b/d/e.go`, true},
+		{`{a,b/{c,d}}/e.go`, `b/e.go`, false},
+		{`a/{,b/}c.go`, `a/c.go`, true},
+		{`a/{b`, `a/{b`, true},
+
+		// Escapes match the character itself.
+		{`a/\*.go`, `a/*.go`, true},
+		{`a/\*.go`, `a/b.go`, false},
+		{`a/\**`, `a/*b.go`, true},
+		{`a/\**`, `a/b.go`, false},
+		{`\**`, `*/b.go`, false},
+		{`a/\?.go`, `a/?.go`, true},
+		{`a/\{b,c}.go`, `a/{b,c}.go`, true},
+		{`a/\{b,c}.go`, `a/b.go`, false},
+		{`a/\[b].go`, `a/[b].go`, true},
+		{`My Lib/**`, `My Lib/a.go`, true},
+	}
+
+	for _, test := range tests {
+		got, err := globMatch(test.pattern, test.name)
+		if err != nil {
+			t.Errorf("globMatch(%q, %q): unexpected error: %v", test.pattern, test.name, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", test.pattern, test.name, got, test.want)
+		}
+	}
+}
+
+func TestGlobMatchMalformed(t *testing.T) {
+	if _, err := globMatch(`a/[b.go`, `a/b.go`); err == nil {
+		t.Errorf("globMatch with an unclosed class: got no error")
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{`a/b.go`, []string{`a/b.go`}},
+		{`a.{go,js}`, []string{`a.go`, `a.js`}},
+		{`{a,b}/{c,d}`, []string{`a/c`, `a/d`, `b/c`, `b/d`}},
+		{`{a,b{c,d}}`, []string{`a`, `bc`, `bd`}},
+		{`a{,b}`, []string{`a`, `ab`}},
+		{`a{b}`, []string{`ab`}},
+		{`a\{b,c}`, []string{`a\{b,c}`}},
+		{`a{b\,c,d}`, []string{`ab\,c`, `ad`}},
+		{`a{b,c`, []string{`a{b,c`}},
+		{`a}b`, []string{`a}b`}},
+	}
+
+	for _, test := range tests {
+		if got := expandBraces(test.pattern); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("expandBraces(%q) = %q, want %q", test.pattern, got, test.want)
+		}
+	}
+}
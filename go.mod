@@ -2,4 +2,7 @@ module github.com/comcast/weasel
 
 go 1.13
 
-require github.com/google/licenseclassifier v0.0.0-20200402202327-879cb1424de0
+require (
+	github.com/google/licenseclassifier v0.0.0-20200402202327-879cb1424de0
+	github.com/sergi/go-diff v1.0.0
+)
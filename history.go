@@ -0,0 +1,515 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// HistoryReport is the result of scanning the commits in a range of git
+// history.
+type HistoryReport struct {
+	Range    string           `json:"range"`
+	Commits  int              `json:"commits"`
+	Problems []HistoryProblem `json:"problems"`
+	Failed   bool             `json:"failed"`
+}
+
+// HistoryProblem is a license that failed the check in a file, from the
+// first commit in the range that introduced it.
+type HistoryProblem struct {
+	License string `json:"license"`
+	Path    string `json:"path"`
+	Commit  string `json:"commit"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	// RemovedIn is the commit that deleted the file or its license, if it
+	// was removed before the end of the range.
+	RemovedIn string `json:"removedIn,omitempty"`
+}
+
+// historyFormats are the formats a HistoryReport can be written in.
+var historyFormats = map[string]func(w io.Writer, r HistoryReport){
+	"text": writeHistoryText,
+	"json": writeHistoryJSON,
+}
+
+// historyCommand scans the files added or changed by each commit in a range
+// of history, reading them from git rather than checking out each commit,
+// and reports the first commit in which each problematic license appeared.
+func historyCommand(args []string) int {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	var format string
+	flags.StringVar(&format, "format", "text", "Output format: text or json.")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: weasel history [-format text|json] <from>..<to> | <from> <to>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	var revRange string
+	switch flags.NArg() {
+	case 1:
+		revRange = flags.Arg(0)
+	case 2:
+		revRange = flags.Arg(0) + `..` + flags.Arg(1)
+	default:
+		flags.Usage()
+		return 1
+	}
+	write, ok := historyFormats[format]
+	if !ok {
+		fmt.Println("Unknown report format: " + format)
+		return 1
+	}
+
+	if _, err := enterProject(`.`); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	if !hasGit || tmpGitDir != `` {
+		fmt.Println("weasel history must be run in a git repository")
+		return 1
+	}
+	if err := loadProject(); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	r, err := scanHistory(revRange)
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	write(os.Stdout, r)
+	if r.Failed {
+		return 1
+	}
+	return 0
+}
+
+// historyCommit is a commit in the scanned range.
+type historyCommit struct {
+	ID     string
+	Author string
+	Date   string
+}
+
+// historyChange is a file added, changed or deleted by a commit.
+type historyChange struct {
+	Mode   string
+	Blob   string
+	Status byte
+	Path   string
+}
+
+// scanHistory classifies every blob added or changed by the commits in
+// revRange, oldest first. Of a merge commit, only the files that differ from
+// all of its parents are classified, as the rest come from the commits it
+// merges. Files are checked against the overrides, debian/copyright and
+// projects of the commit that changed them.
+func scanHistory(revRange string) (HistoryReport, error) {
+	r := HistoryReport{Range: revRange, Problems: []HistoryProblem{}}
+
+	commits, err := historyCommits(revRange)
+	if err != nil {
+		return r, err
+	}
+	r.Commits = len(commits)
+
+	objects, err := openGitObjects()
+	if err != nil {
+		return r, err
+	}
+	defer objects.Close()
+
+	cache := make(map[string][]License)      // By blob, whether it is a license file, and the configuration of large files.
+	open := make(map[string]map[License]int) // Problems not yet removed, by path.
+	var state *historyState
+	for _, commit := range commits {
+		changes, err := historyChanges(commit.ID)
+		if err != nil {
+			return r, err
+		}
+		if state == nil || changesProject(changes) {
+			if state, err = loadHistoryState(objects, commit.ID); err != nil {
+				return r, err
+			}
+		}
+		for _, change := range changes {
+			var problems []License
+			if change.Status != 'D' && change.Mode != `120000` && change.Mode != `160000` && !Ignored(change.Path) {
+				lics, err := historyLicenses(objects, cache, state, commit.ID, change)
+				if err != nil {
+					return r, err
+				}
+				problems = historyProblems(state, change.Path, lics)
+			}
+
+			found := make(map[License]bool)
+			for _, lic := range problems {
+				found[lic] = true
+				if i, ok := open[change.Path][lic]; ok {
+					r.Problems[i].RemovedIn = ``
+					continue
+				}
+				if open[change.Path] == nil {
+					open[change.Path] = make(map[License]int)
+				}
+				open[change.Path][lic] = len(r.Problems)
+				r.Problems = append(r.Problems, HistoryProblem{
+					License: string(lic),
+					Path:    change.Path,
+					Commit:  commit.ID,
+					Author:  commit.Author,
+					Date:    commit.Date,
+				})
+			}
+			for lic, i := range open[change.Path] {
+				if !found[lic] && r.Problems[i].RemovedIn == `` {
+					r.Problems[i].RemovedIn = commit.ID
+				}
+			}
+		}
+	}
+	r.Failed = len(r.Problems) != 0
+	return r, nil
+}
+
+// historyCommits lists the commits in revRange, oldest first.
+func historyCommits(revRange string) ([]historyCommit, error) {
+	b, err := exec.Command(`git`, `log`, `--reverse`, `--format=%H%x00%an <%ae>%x00%aI`, revRange, `--`).Output()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot list the commits in %s: %v", revRange, gitError(err))
+	}
+	var commits []historyCommit
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		parts := strings.Split(line, "\x00")
+		if len(parts) == 3 {
+			commits = append(commits, historyCommit{parts[0], parts[1], parts[2]})
+		}
+	}
+	return commits, nil
+}
+
+// historyChanges lists the files in the working directory that a commit
+// added, changed or deleted, compared with its parent, or for a merge, the
+// files that differ from all of its parents. Renames are reported as a
+// deletion and an addition. Paths are relative to the working directory.
+func historyChanges(commit string) ([]historyChange, error) {
+	b, err := exec.Command(`git`, `diff-tree`, `-r`, `-z`, `-c`, `--root`, `--relative`, `--no-renames`, `--no-commit-id`, commit).Output()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot list the changes of %s: %v", commit, gitError(err))
+	}
+	return parseHistoryChanges(commit, b)
+}
+
+// parseHistoryChanges parses the output of historyChanges's `git diff-tree`
+// of commit.
+func parseHistoryChanges(commit string, b []byte) ([]historyChange, error) {
+	// Each change is a `:` for each parent, the modes of the file in each
+	// parent and then in the commit, its blobs likewise, and its status, then
+	// its path, separated by NULs. A merge's result is deleted if its mode is
+	// 000000.
+	var changes []historyChange
+	fields := strings.Split(strings.TrimSuffix(string(b), "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		parents := len(fields[i]) - len(strings.TrimLeft(fields[i], `:`))
+		info := strings.Fields(fields[i][parents:])
+		if parents == 0 || len(info) != 2*parents+3 || info[len(info)-1] == `` {
+			return nil, fmt.Errorf("Cannot parse the changes of %s: %q", commit, fields[i])
+		}
+		change := historyChange{
+			Mode:   info[parents],
+			Blob:   info[2*parents+1],
+			Status: info[len(info)-1][0],
+			Path:   fields[i+1],
+		}
+		if change.Mode == `000000` {
+			change.Status = 'D'
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// historyState is what the files of a commit are checked against: the
+// overrides, debian/copyright and projects as they were in it.
+type historyState struct {
+	filters  []overrideFilter
+	debian   []debianRule
+	projects []*Project
+}
+
+// isProjectFile reports whether name is read by loadHistoryState, so that a
+// change to it changes the state.
+func isProjectFile(name string) bool {
+	base := path.Base(name)
+	return base == `LICENSE` || base == configFile || name == debianCopyrightFile ||
+		strings.HasSuffix(name, `.dependency_license`) || strings.Contains(name, `.dependency_licenses/`)
+}
+
+// changesProject reports whether any of changes is to a file read by
+// loadHistoryState.
+func changesProject(changes []historyChange) bool {
+	for _, change := range changes {
+		if isProjectFile(change.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadHistoryState reads the overrides, debian/copyright and projects of
+// the working directory as they were in commit, as loadProject does for the
+// working tree. The configuration of the root project, custom licenses and
+// REUSE annotations are those of the working tree.
+func loadHistoryState(objects *gitObjects, commit string) (*historyState, error) {
+	b, err := exec.Command(`git`, `ls-tree`, `-r`, `-z`, `--name-only`, commit).Output()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot list the files of %s: %v", commit, gitError(err))
+	}
+	readFile := func(name string) ([]byte, error) {
+		_, content, ok, err := objects.read(commit + `:./` + name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, &os.PathError{Op: `read`, Path: commit + `:` + name, Err: os.ErrNotExist}
+		}
+		return content, nil
+	}
+
+	state := &historyState{}
+	var dirs []string
+	for _, name := range strings.Split(strings.TrimSuffix(string(b), "\x00"), "\x00") {
+		// LICENSE files are read by nestProjects, for the projects in dirs.
+		if name == `` || !isProjectFile(name) || path.Base(name) == `LICENSE` {
+			continue
+		}
+		content, err := readFile(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch base, dir := path.Base(name), path.Dir(name); {
+		case name == debianCopyrightFile:
+			if state.debian, err = parseDebianCopyright(bytes.NewReader(content)); err != nil {
+				return nil, fmt.Errorf("%s: %v", commit, err)
+			}
+		case base == configFile:
+			if dir != `.` {
+				dirs = append(dirs, dir)
+			}
+		default:
+			filters, err := parseOverrideFile(name, strings.Contains(name, `.dependency_licenses/`), bytes.NewReader(content))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", commit, err)
+			}
+			state.filters = append(state.filters, filters...)
+		}
+	}
+
+	lines, err := readLicenseLinesWith(`.`, readFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	root := &Project{Dir: `.`, Config: config, Documented: parseDocumented(lines, `.`)}
+	if state.projects, err = nestProjects(root, dirs, readFile); err != nil {
+		return nil, fmt.Errorf("%s: %v", commit, err)
+	}
+	return state, nil
+}
+
+// historyLicenses classifies a blob added or changed by a commit, applying
+// the overrides and debian/copyright of state and inheriting from the
+// LICENSE files in the commit's tree, as classify and scan do for files in
+// the working tree.
+func historyLicenses(objects *gitObjects, cache map[string][]License, state *historyState, commit string, change historyChange) ([]License, error) {
+	blobLicenses := func(name string, rev string) ([]License, error) {
+		id, content, ok, err := objects.read(rev)
+		if err != nil || !ok {
+			return nil, err
+		}
+		c := classifierIn(state.projects, name)
+		key := id + strconv.FormatBool(isLicenseFile(name)) + fmt.Sprint(c.large)
+		if lics, ok := cache[key]; ok {
+			return lics, nil
+		}
+		var lics []License
+		if len(content) == 0 {
+			lics = []License{License(`Empty`)}
+		} else if lics, err = c.contentLicenses(name, content); err != nil {
+			lics = []License{License("Error: " + err.Error() + "!")}
+		}
+		cache[key] = lics
+		return lics, nil
+	}
+
+	licenses, err := blobLicenses(change.Path, change.Blob)
+	if err != nil {
+		return nil, err
+	}
+	overrides := overridesIn(state.filters, change.Path)
+	if isTooLarge(licenses) && len(overrides) != 0 {
+		licenses = nil
+	}
+	if len(overrides) == 0 {
+		licenses = debianLicenses(state.debian, change.Path, licenses)
+	}
+	lics := Collide(Uniq(append(overrides, licenses...)))
+	if len(lics) != 0 {
+		return lics, nil
+	}
+
+	_, inherited := inheritIn(state.projects, change.Path, func(licPath string) []License {
+		if Ignored(licPath) {
+			return nil
+		}
+		lics, err := blobLicenses(licPath, commit+`:./`+licPath)
+		if err != nil {
+			return nil
+		}
+		return Collide(Uniq(append(overridesIn(state.filters, licPath), lics...)))
+	})
+	return inherited, nil
+}
+
+// historyProblems returns the licenses of a file that fail the check against
+// the projects of state, as marked by markUndocumented, or "Unknown!" if it
+// has none.
+func historyProblems(state *historyState, name string, lics []License) []License {
+	if len(lics) == 0 {
+		return []License{License(`Unknown!`)}
+	}
+	if _, ignore, _ := verdict(lics); ignore {
+		return nil
+	}
+	lics = append([]License(nil), lics...)
+	markUndocumentedIn(state.projects, name, lics)
+
+	var problems []License
+	for _, lic := range lics {
+		if strings.HasSuffix(string(lic), `!`) {
+			problems = append(problems, lic)
+		}
+	}
+	return problems
+}
+
+// gitObjects reads objects from the git repository in the working directory
+// through a single `git cat-file --batch` process.
+type gitObjects struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Reader
+}
+
+func openGitObjects() (*gitObjects, error) {
+	cmd := exec.Command(`git`, `cat-file`, `--batch`)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("Cannot run git cat-file: %v", err)
+	}
+	return &gitObjects{cmd, in, bufio.NewReader(out)}, nil
+}
+
+// read returns the id and content of the blob named by rev, such as a blob id
+// or "<commit>:<path>". It returns false if there is no such blob.
+func (g *gitObjects) read(rev string) (id string, content []byte, ok bool, err error) {
+	if _, err := fmt.Fprintln(g.in, rev); err != nil {
+		return ``, nil, false, fmt.Errorf("Cannot read %s from git: %v", rev, err)
+	}
+	header, err := g.out.ReadString('\n')
+	if err != nil {
+		return ``, nil, false, fmt.Errorf("Cannot read %s from git: %v", rev, err)
+	}
+
+	// The header is "<id> <type> <size>", or "<rev> missing".
+	fields := strings.Fields(header)
+	if len(fields) != 3 {
+		return ``, nil, false, nil
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return ``, nil, false, fmt.Errorf("Cannot read %s from git: bad header %q", rev, header)
+	}
+	content = make([]byte, size+1) // The content is followed by a newline.
+	if _, err := io.ReadFull(g.out, content); err != nil {
+		return ``, nil, false, fmt.Errorf("Cannot read %s from git: %v", rev, err)
+	}
+	if fields[1] != `blob` {
+		return ``, nil, false, nil
+	}
+	return fields[0], content[:size], true, nil
+}
+
+func (g *gitObjects) Close() error {
+	g.in.Close()
+	return g.cmd.Wait()
+}
+
+// gitError returns the message git wrote to stderr for a failed command, if
+// any.
+func gitError(err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) != 0 {
+		return fmt.Errorf("%s", bytes.TrimSpace(exitErr.Stderr))
+	}
+	return err
+}
+
+// writeHistoryText writes each problem on a line, in the form of the report
+// of a scan, followed by the commit that introduced it.
+func writeHistoryText(w io.Writer, r HistoryReport) {
+	fmt.Fprintf(w, "In range: %s (%d commits)\n", r.Range, r.Commits)
+	for _, p := range r.Problems {
+		fmt.Fprintf(w, "%-6s%40s %s\n", "Error", p.License, p.Path)
+		fmt.Fprintf(w, "%47sintroduced in %.12s by %s on %s\n", "", p.Commit, p.Author, p.Date)
+		if p.RemovedIn != `` {
+			fmt.Fprintf(w, "%47sremoved in %.12s\n", "", p.RemovedIn)
+		}
+	}
+}
+
+func writeHistoryJSON(w io.Writer, r HistoryReport) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(``, `  `)
+	enc.Encode(r)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHistoryChanges(t *testing.T) {
+	const (
+		blobA = `78981922613b2afb6025042ff6bd878ac1994e85`
+		blobB = `61780798228d17af2d34fce4cfbdf35556832472`
+		blobC = `20b117fdd3804508359ec883abe519486f0d19dd`
+		blobD = `4bcfe98e640c8284511312660fb8709b0afa888e`
+		none  = `0000000000000000000000000000000000000000`
+	)
+	tests := []struct {
+		name string
+		out  string
+		want []historyChange
+		err  string
+	}{
+		{
+			name: "root commit",
+			out:  ":000000 100644 " + none + " " + blobA + " A\x00a\x00:000000 100755 " + none + " " + blobB + " A\x00bin/my tool\x00",
+			want: []historyChange{{`100644`, blobA, 'A', `a`}, {`100755`, blobB, 'A', `bin/my tool`}},
+		},
+		{
+			name: "modified and deleted",
+			out:  ":100644 100644 " + blobA + " " + blobC + " M\x00a\x00:100644 000000 " + blobB + " " + none + " D\x00b\x00",
+			want: []historyChange{{`100644`, blobC, 'M', `a`}, {`000000`, none, 'D', `b`}},
+		},
+		{
+			name: "merge",
+			out: "::100644 100644 100644 " + blobA + " " + blobB + " " + blobC + " MM\x00a\x00" +
+				"::100644 100644 000000 " + blobD + " " + blobD + " " + none + " DD\x00d\x00" +
+				"::000000 000000 100644 " + none + " " + none + " " + blobB + " AA\x00e\x00",
+			want: []historyChange{{`100644`, blobC, 'M', `a`}, {`000000`, none, 'D', `d`}, {`100644`, blobB, 'A', `e`}},
+		},
+		{
+			name: "merge of three parents",
+			out:  ":::100644 100644 100644 100644 " + blobA + " " + blobB + " " + blobD + " " + blobC + " MMM\x00a\x00",
+			want: []historyChange{{`100644`, blobC, 'M', `a`}},
+		},
+		{
+			name: "merge deleting a file one parent modified",
+			out:  "::100644 100644 000000 " + blobA + " " + blobB + " " + none + " MD\x00a\x00",
+			want: []historyChange{{`000000`, none, 'D', `a`}},
+		},
+		{
+			name: "no changes",
+			out:  "",
+		},
+		{
+			name: "no colon",
+			out:  "100644 100644 " + blobA + " " + blobC + " M\x00a\x00",
+			err:  "Cannot parse the changes of abc",
+		},
+		{
+			name: "too few fields for the parents",
+			out:  "::100644 100644 " + blobA + " " + blobC + " M\x00a\x00",
+			err:  "Cannot parse the changes of abc",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseHistoryChanges(`abc`, []byte(test.out))
+			if test.err != `` {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want one containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestChangesProject(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{`LICENSE`, true},
+		{`sdk/LICENSE`, true},
+		{`.weasel.json`, true},
+		{`sdk/.weasel.json`, true},
+		{`debian/copyright`, true},
+		{`sdk/debian/copyright`, false},
+		{`vendor/.dependency_license`, true},
+		{`.dependency_licenses/foo`, true},
+		{`main.go`, false},
+		{`LICENSE.md`, false},
+	}
+
+	for _, test := range tests {
+		if got := changesProject([]historyChange{{Path: `main.go`}, {Path: test.path}}); got != test.want {
+			t.Errorf("changesProject(%q) = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestHistoryProblems(t *testing.T) {
+	root := &Project{Dir: `.`, Config: Config{License: `Apache-2.0`}}
+	for i, text := range []string{`vendor/**`, `MIT sdk/LICENSE`} {
+		doc := parseDocLine(text)
+		doc.Line = i + 1
+		doc.Section = &Section{}
+		root.Documented = append(root.Documented, doc)
+	}
+	sdk := &Project{Dir: `sdk`, Config: Config{License: `MIT`}}
+	state := &historyState{projects: []*Project{sdk, root}}
+
+	tests := []struct {
+		name string
+		lics []License
+		want []License
+	}{
+		{`main.go`, nil, []License{`Unknown!`}},
+		{`main.go`, []License{`Apache-2.0`}, nil},
+		{`main.go`, []License{`GPL-2.0-only`, `Apache-2.0`}, []License{`GPL-2.0-only!`}},
+		{`main.go`, []License{`GPL-2.0-only`, `Ignore`}, nil},
+		{`vendor/a.go`, []License{`GPL-2.0-only`}, nil},
+		{`sdk/a.go`, []License{`MIT`}, nil},
+		{`sdk/a.go`, []License{`Apache-2.0`}, []License{`Apache-2.0!`}},
+		{`sdk/LICENSE`, []License{`MIT`}, nil},
+	}
+
+	for _, test := range tests {
+		lics := append([]License(nil), test.lics...)
+		if got := historyProblems(state, test.name, lics); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("historyProblems(%q, %q) = %q, want %q", test.name, test.lics, got, test.want)
+		}
+		if !reflect.DeepEqual(lics, test.lics) {
+			t.Errorf("historyProblems(%q) changed its licenses to %q", test.name, lics)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// hookMarker is in every hook weasel writes, so it can tell its own hooks
+// from others it mustn't replace.
+const hookMarker = `# Installed by weasel hook install.`
+
+// hookCommand manages the git hooks that run weasel.
+func hookCommand(args []string) int {
+	flags := flag.NewFlagSet("hook", flag.ExitOnError)
+	force := flags.Bool("f", false, "Replace an existing pre-commit hook that weasel didn't install.")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: weasel hook install [-f]")
+		flags.PrintDefaults()
+	}
+	if len(args) == 0 || args[0] != `install` {
+		flags.Usage()
+		return 1
+	}
+	flags.Parse(args[1:])
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 1
+	}
+
+	// git-path honors core.hooksPath and worktrees.
+	b, err := exec.Command(`git`, `rev-parse`, `--git-path`, `hooks/pre-commit`).Output()
+	if err != nil {
+		fmt.Println("Cannot find the git hooks directory: " + gitError(err).Error())
+		return 1
+	}
+	hook := strings.TrimSpace(string(b))
+
+	if existing, err := ioutil.ReadFile(hook); err == nil && !bytes.Contains(existing, []byte(hookMarker)) && !*force {
+		fmt.Println(hook + " already exists, pass -f to replace it")
+		return 1
+	}
+
+	// Run the weasel on the PATH if there is one, so the hook keeps working
+	// when weasel is upgraded.
+	weasel := `weasel`
+	if _, err := exec.LookPath(weasel); err != nil {
+		if weasel, err = os.Executable(); err != nil {
+			fmt.Println("Cannot find weasel: " + err.Error())
+			return 1
+		}
+	}
+	script := fmt.Sprintf("#!/bin/sh\n%s\n# Checks the licenses of the staged content of the files being committed.\nexec %s check -staged\n", hookMarker, shellQuote(weasel))
+
+	if err := os.MkdirAll(filepath.Dir(hook), 0777); err != nil {
+		fmt.Println("Cannot create the git hooks directory: " + err.Error())
+		return 1
+	}
+	if err := ioutil.WriteFile(hook, []byte(script), 0755); err != nil {
+		fmt.Println("Cannot write " + hook + ": " + err.Error())
+		return 1
+	}
+	if err := os.Chmod(hook, 0755); err != nil {
+		fmt.Println("Cannot make " + hook + " executable: " + err.Error())
+		return 1
+	}
+	fmt.Println("Installed " + hook)
+	return 0
+}
+
+// shellQuote quotes s for sh, if it needs to be.
+func shellQuote(s string) string {
+	if s != `` && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune(`/._-+`, r))
+	}) < 0 {
+		return s
+	}
+	return `'` + strings.Replace(s, `'`, `'\''`, -1) + `'`
+}
+
+// stagedFiles returns the files in the working directory with staged
+// changes, other than deletions, symlinks and submodules, limited to those
+// in paths if there are any. Paths are relative to the working directory.
+func stagedFiles(paths []string) ([]string, error) {
+	args := append([]string{`diff`, `--cached`, `--raw`, `-z`, `--relative`, `--no-renames`, `--diff-filter=ACMRT`, `--`}, paths...)
+	b, err := exec.Command(`git`, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("Cannot list the staged files: %v", gitError(err))
+	}
+
+	// Each change is ":<old mode> <new mode> <old blob> <new blob> <status>",
+	// then its path, separated by NULs.
+	var names []string
+	fields := strings.Split(strings.TrimSuffix(string(b), "\x00"), "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		info := strings.Fields(strings.TrimPrefix(fields[i], `:`))
+		if len(info) != 5 {
+			return nil, fmt.Errorf("Cannot parse the staged files: %q", fields[i])
+		}
+		if mode := info[1]; mode != `120000` && mode != `160000` {
+			names = append(names, fields[i+1])
+		}
+	}
+	return names, nil
+}
+
+// indexContents reads the staged contents of files from the index, falling
+// back to the working tree for files that aren't in it.
+type indexContents struct {
+	objects *gitObjects
+	lock    sync.Mutex // Guards objects, blobs and missing.
+	blobs   map[string][]byte
+	missing map[string]bool
+}
+
+func newIndexContents() (*indexContents, error) {
+	objects, err := openGitObjects()
+	if err != nil {
+		return nil, err
+	}
+	return &indexContents{objects: objects, blobs: make(map[string][]byte), missing: make(map[string]bool)}, nil
+}
+
+// read returns the staged content of name, relative to the working
+// directory, or false if it isn't in the index.
+func (x *indexContents) read(name string) ([]byte, bool, error) {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+	if b, ok := x.blobs[name]; ok {
+		return b, true, nil
+	}
+	if x.missing[name] {
+		return nil, false, nil
+	}
+	_, content, ok, err := x.objects.read(`:./` + name)
+	if err != nil {
+		return nil, false, err
+	}
+	if !ok {
+		x.missing[name] = true
+		return nil, false, nil
+	}
+	x.blobs[name] = content
+	return content, true, nil
+}
+
+func (x *indexContents) licenses(source string) ([]License, error) {
+	b, ok, err := x.read(source)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return fileLicenses(source)
+	}
+	return contentLicenses(source, b)
+}
+
+func (x *indexContents) copyrights(source string) ([]Copyright, error) {
+	b, ok, err := x.read(source)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return fileCopyrights(source)
+	}
+	return contentCopyrights(source, b)
+}
+
+func (x *indexContents) Close() error {
+	return x.objects.Close()
+}
@@ -22,6 +22,8 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"strings"
+	"time"
 )
 
 var hasGit bool
@@ -33,6 +35,7 @@ func init() {
 }
 
 func Ignored(f string) bool {
+	defer spent(&timings.Git, time.Now())
 	if hasGit {
 		if tmpGitDir != "" {
 			_, err := exec.Command(`git`, `--git-dir=`+tmpGitDir+"/.git", `check-ignore`, `-q`, f).CombinedOutput()
@@ -45,9 +48,31 @@ func Ignored(f string) bool {
 	return false
 }
 
+// IgnoreRule returns the gitignore rule that causes f to be ignored, in the
+// form "source:line:pattern", or the empty string if f is not ignored, as
+// Ignored decides.
+func IgnoreRule(f string) string {
+	if !Ignored(f) {
+		return ``
+	}
+	defer spent(&timings.Git, time.Now())
+	// -v prints the last matching rule even if it is a negation that
+	// re-includes f, so it only names the rule.
+	args := []string{`check-ignore`, `-v`, f}
+	if tmpGitDir != "" {
+		args = append([]string{`--git-dir=` + tmpGitDir + "/.git"}, args...)
+	}
+	b, _ := exec.Command(`git`, args...).Output()
+	if rule := strings.SplitN(strings.TrimSpace(string(b)), "\t", 2)[0]; rule != `` {
+		return rule
+	}
+	return `a gitignore rule`
+}
+
 var tmpGitDir string
 
 func initGit() {
+	defer spent(&timings.Git, time.Now())
 	if hasGit {
 		if _, err := os.Stat(`.git`); os.IsNotExist(err) {
 			dir, err := ioutil.TempDir("", "weasel-git-")
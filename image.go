@@ -0,0 +1,625 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// The media types of OCI and Docker manifests that list the manifests of an
+// image for several platforms, rather than its layers.
+var imageIndexTypes = map[string]bool{
+	`application/vnd.oci.image.index.v1+json`:                   true,
+	`application/vnd.docker.distribution.manifest.list.v2+json`: true,
+}
+
+// The prefixes of whiteout files, which delete files of lower layers.
+const (
+	whiteoutPrefix = `.wh.`
+	whiteoutOpaque = `.wh..wh..opq` // Deletes everything in its directory.
+)
+
+// imageLayer is a layer of an image: a tar archive, possibly compressed, of
+// the files it adds, changes or deletes.
+type imageLayer struct {
+	Digest string
+	Path   string // The archive, in the image's directory.
+}
+
+// ImageReport is the result of a scan of a container image.
+type ImageReport struct {
+	Image    string          `json:"image"`
+	Layers   []LayerReport   `json:"layers"`
+	Packages []PackageReport `json:"packages"`
+	Files    []ImageFile     `json:"files"`
+	Failed   bool            `json:"failed"`
+}
+
+// LayerReport counts the licenses of the files each layer of an image
+// contributes to its final filesystem.
+type LayerReport struct {
+	Digest   string         `json:"digest"`
+	Files    int            `json:"files"`
+	Licenses []LicenseCount `json:"licenses"`
+}
+
+// LicenseCount is the number of files with a license.
+type LicenseCount struct {
+	License string `json:"license"`
+	Files   int    `json:"files"`
+}
+
+// ImageFile is a file in the final filesystem of an image.
+type ImageFile struct {
+	Name     string    `json:"name"`
+	Layer    int       `json:"layer"` // The index of the layer that last wrote it.
+	Licenses []License `json:"licenses"`
+	Package  string    `json:"package,omitempty"`
+	// Unaccounted is set when neither the file nor the package owning it has
+	// a known license.
+	Unaccounted bool `json:"unaccounted,omitempty"`
+}
+
+// imageFormats are the formats an ImageReport can be written in.
+var imageFormats = map[string]func(w io.Writer, r ImageReport, quiet bool){
+	"text": writeImageText,
+	"json": writeImageJSON,
+}
+
+// imageCommand scans a container image, from a `docker save` archive or an
+// OCI image layout directory, and reports the licenses of its files by layer
+// and by the packages installed in it.
+func imageCommand(args []string) int {
+	flags := flag.NewFlagSet("image", flag.ExitOnError)
+	var all bool
+	flags.BoolVar(&all, "a", false, "Print all files and their licenses, not just unaccounted files.")
+	var format string
+	flags.StringVar(&format, "format", "text", "Output format: text or json.")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: weasel image [-a] [-format text|json] <image.tar|oci-layout-dir>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 1
+	}
+	write, ok := imageFormats[format]
+	if !ok {
+		fmt.Println("Unknown report format: " + format)
+		return 1
+	}
+
+	r, err := scanImage(flags.Arg(0))
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	write(os.Stdout, r, !all)
+	if r.Failed {
+		return 1
+	}
+	return 0
+}
+
+// scanImage unpacks the layers of an image into a temporary directory and
+// classifies the files of the resulting filesystem.
+func scanImage(src string) (ImageReport, error) {
+	var r ImageReport
+
+	dir := src
+	if fi, err := os.Stat(src); err != nil {
+		return r, err
+	} else if !fi.IsDir() {
+		tmp, err := ioutil.TempDir(``, `weasel-image-`)
+		if err != nil {
+			return r, err
+		}
+		defer os.RemoveAll(tmp)
+		if err := extractArchive(src, tmp); err != nil {
+			return r, fmt.Errorf("Cannot read image archive %s: %v", src, err)
+		}
+		dir = tmp
+	}
+
+	name, layers, err := imageLayers(dir)
+	if err != nil {
+		return r, fmt.Errorf("Cannot read image %s: %v", src, err)
+	}
+	r.Image = name
+	if r.Image == `` {
+		r.Image = src
+	}
+
+	rootfs, err := ioutil.TempDir(``, `weasel-rootfs-`)
+	if err != nil {
+		return r, err
+	}
+	defer os.RemoveAll(rootfs)
+
+	owners := make(map[string]int)
+	for i, layer := range layers {
+		if err := applyLayer(layer, i, rootfs, owners); err != nil {
+			return r, fmt.Errorf("Cannot unpack layer %s: %v", layer.Digest, err)
+		}
+	}
+
+	files, err := classifyTree(rootfs, owners)
+	if err != nil {
+		return r, err
+	}
+	packages, err := imagePackages(rootfs, files)
+	if err != nil {
+		return r, err
+	}
+	return buildImageReport(r, layers, owners, files, packages), nil
+}
+
+// imageLayers reads the layers of the image in dir, bottom first, from the
+// manifest.json of `docker save`, or else from the index.json of an OCI
+// image layout.
+func imageLayers(dir string) (string, []imageLayer, error) {
+	if b, err := ioutil.ReadFile(filepath.Join(dir, `manifest.json`)); err == nil {
+		var manifests []struct {
+			RepoTags []string
+			Layers   []string
+		}
+		if err := json.Unmarshal(b, &manifests); err != nil {
+			return ``, nil, fmt.Errorf("Malformed manifest.json: %v", err)
+		}
+		if len(manifests) == 0 {
+			return ``, nil, fmt.Errorf("manifest.json lists no images")
+		}
+		var layers []imageLayer
+		for _, l := range manifests[0].Layers {
+			layers = append(layers, imageLayer{Digest: l, Path: filepath.Join(dir, filepath.FromSlash(l))})
+		}
+		return strings.Join(manifests[0].RepoTags, `, `), layers, nil
+	}
+
+	type descriptor struct {
+		MediaType   string            `json:"mediaType"`
+		Digest      string            `json:"digest"`
+		Annotations map[string]string `json:"annotations"`
+		Platform    *struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+		} `json:"platform"`
+	}
+	var manifest struct {
+		MediaType string       `json:"mediaType"`
+		Manifests []descriptor `json:"manifests"`
+		Layers    []descriptor `json:"layers"`
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, `index.json`))
+	if err != nil {
+		return ``, nil, fmt.Errorf("Neither manifest.json nor index.json found")
+	}
+	name := ``
+	for depth := 0; ; depth++ {
+		manifest.MediaType, manifest.Manifests, manifest.Layers = ``, nil, nil
+		if err := json.Unmarshal(b, &manifest); err != nil {
+			return ``, nil, fmt.Errorf("Malformed manifest: %v", err)
+		}
+		if len(manifest.Manifests) == 0 || depth > 8 {
+			break
+		}
+
+		chosen := manifest.Manifests[0]
+		for _, m := range manifest.Manifests {
+			if m.Platform != nil && m.Platform.OS == `linux` && m.Platform.Architecture == runtime.GOARCH {
+				chosen = m
+				break
+			}
+		}
+		if ref := chosen.Annotations[`org.opencontainers.image.ref.name`]; ref != `` && name == `` {
+			name = ref
+		}
+		if b, err = ioutil.ReadFile(blobPath(dir, chosen.Digest)); err != nil {
+			return ``, nil, fmt.Errorf("Cannot read manifest %s: %v", chosen.Digest, err)
+		}
+	}
+	if imageIndexTypes[manifest.MediaType] || len(manifest.Layers) == 0 {
+		return ``, nil, fmt.Errorf("No image manifest with layers found")
+	}
+
+	var layers []imageLayer
+	for _, l := range manifest.Layers {
+		layers = append(layers, imageLayer{Digest: l.Digest, Path: blobPath(dir, l.Digest)})
+	}
+	return name, layers, nil
+}
+
+// blobPath returns the path of a blob in an OCI image layout by its digest,
+// such as "sha256:abc...".
+func blobPath(dir string, digest string) string {
+	parts := strings.SplitN(digest, `:`, 2)
+	if len(parts) != 2 {
+		return filepath.Join(dir, `blobs`, filepath.Base(digest))
+	}
+	return filepath.Join(dir, `blobs`, filepath.Base(parts[0]), filepath.Base(parts[1]))
+}
+
+// openLayer opens a layer's archive, decompressing it if it is gzipped.
+func openLayer(name string) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	b := bufio.NewReader(f)
+	magic, _ := b.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		z, err := gzip.NewReader(b)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{z, f}, nil
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		f.Close()
+		return nil, fmt.Errorf("zstd compressed layers are not supported")
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{b, f}, nil
+}
+
+// imagePath cleans the name of an archive entry into a relative,
+// slash-separated path that can't escape the directory it's unpacked in. It
+// returns the empty string for the root.
+func imagePath(name string) string {
+	return strings.TrimPrefix(path.Clean(`/`+name), `/`)
+}
+
+// extractArchive unpacks the directories and regular files of a tar archive,
+// such as the output of `docker save`, into dir.
+func extractArchive(name string, dir string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	t := tar.NewReader(f)
+	for {
+		hdr, err := t.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		p := imagePath(hdr.Name)
+		if p == `` || hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := writeImageFile(filepath.Join(dir, filepath.FromSlash(p)), t); err != nil {
+			return err
+		}
+	}
+}
+
+// applyLayer unpacks a layer over the filesystem in rootfs, deleting the
+// files its whiteouts hide. owners records the index of the layer that last
+// wrote each regular file. Symbolic links, devices and the like are not
+// unpacked, as the scan of a project skips them too.
+func applyLayer(layer imageLayer, index int, rootfs string, owners map[string]int) error {
+	in, err := openLayer(layer.Path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	remove := func(p string, lowerOnly bool) {
+		for name, owner := range owners {
+			if (name == p || strings.HasPrefix(name, p+`/`) || p == ``) && (!lowerOnly || owner < index) {
+				delete(owners, name)
+				os.Remove(filepath.Join(rootfs, filepath.FromSlash(name)))
+			}
+		}
+	}
+
+	t := tar.NewReader(in)
+	for {
+		hdr, err := t.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		p := imagePath(hdr.Name)
+		if p == `` {
+			continue
+		}
+
+		dir, base := path.Split(p)
+		dir = strings.TrimSuffix(dir, `/`)
+		if base == whiteoutOpaque {
+			remove(dir, true)
+			continue
+		}
+		if strings.HasPrefix(base, whiteoutPrefix) {
+			remove(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), false)
+			continue
+		}
+
+		// A file from a lower layer may be in the way of the entry's directory.
+		for parent := path.Dir(p); parent != `.`; parent = path.Dir(parent) {
+			if _, ok := owners[parent]; ok {
+				delete(owners, parent)
+				os.Remove(filepath.Join(rootfs, filepath.FromSlash(parent)))
+			}
+		}
+
+		target := filepath.Join(rootfs, filepath.FromSlash(p))
+		replace := func() {
+			if fi, err := os.Lstat(target); err == nil && fi.IsDir() {
+				remove(p, false)
+				os.RemoveAll(target)
+			} else if err == nil {
+				delete(owners, p)
+				os.Remove(target)
+			}
+		}
+		switch hdr.Typeflag {
+		case tar.TypeReg, tar.TypeRegA:
+			replace()
+			if err := writeImageFile(target, t); err != nil {
+				return err
+			}
+			owners[p] = index
+		case tar.TypeLink:
+			source := imagePath(hdr.Linkname)
+			if _, ok := owners[source]; !ok {
+				continue
+			}
+			f, err := os.Open(filepath.Join(rootfs, filepath.FromSlash(source)))
+			if err != nil {
+				return err
+			}
+			replace()
+			err = writeImageFile(target, f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+			owners[p] = index
+		case tar.TypeDir:
+			if _, ok := owners[p]; ok {
+				replace()
+			}
+		default:
+			replace()
+		}
+	}
+}
+
+// writeImageFile writes the content of an unpacked file, replacing any file
+// in the way of it or its directory.
+func writeImageFile(name string, content io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		// A file from a lower layer is in the way of a directory.
+		for dir := filepath.Dir(name); dir != `.` && dir != `/`; dir = filepath.Dir(dir) {
+			if fi, err := os.Lstat(dir); err == nil && !fi.IsDir() {
+				os.Remove(dir)
+				break
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return err
+		}
+	}
+	if fi, err := os.Lstat(name); err == nil && fi.IsDir() {
+		os.RemoveAll(name)
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// classifyTree classifies every file unpacked into rootfs, with inheritance
+// from LICENSE files, as scan does for a project. Files are named relative
+// to rootfs.
+func classifyTree(rootfs string, owners map[string]int) (map[string][]License, error) {
+	infos := make(map[string]os.FileInfo)
+	var names []string
+	for name := range owners {
+		info, err := os.Lstat(filepath.Join(rootfs, filepath.FromSlash(name)))
+		if err != nil || !info.Mode().IsRegular() {
+			// Replaced by a directory of a later layer.
+			delete(owners, name)
+			continue
+		}
+		infos[name] = info
+		names = append(names, name)
+	}
+
+	files := classifyAll(names, func(name string) []License {
+		return classify(filepath.Join(rootfs, filepath.FromSlash(name)), infos[name])
+	})
+	for name, licenses := range files {
+		if len(licenses) == 0 {
+			if _, inherited := inherit(name, func(licPath string) []License { return files[licPath] }); len(inherited) != 0 {
+				files[name] = inherited
+			}
+		}
+	}
+	return files, nil
+}
+
+// buildImageReport counts the licenses of each layer's files, and finds the
+// files that neither have a license nor belong to a package that has one,
+// other than the package databases themselves.
+func buildImageReport(r ImageReport, layers []imageLayer, owners map[string]int, files map[string][]License, packages []*imagePackage) ImageReport {
+	r.Layers = make([]LayerReport, len(layers))
+	counts := make([]map[string]int, len(layers))
+	for i, layer := range layers {
+		r.Layers[i] = LayerReport{Digest: layer.Digest, Licenses: []LicenseCount{}}
+		counts[i] = make(map[string]int)
+	}
+
+	owner := make(map[string]*imagePackage)
+	r.Packages = []PackageReport{}
+	for _, p := range packages {
+		for _, name := range p.Files {
+			owner[name] = p
+		}
+		r.Packages = append(r.Packages, p.PackageReport)
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	r.Files = []ImageFile{}
+	for _, name := range names {
+		f := ImageFile{Name: name, Layer: owners[name], Licenses: files[name]}
+		if f.Licenses == nil {
+			f.Licenses = []License{}
+		}
+		ids := imageLicenseIDs(files[name])
+		if p := owner[name]; p != nil {
+			f.Package = p.Name
+			if len(ids) == 0 {
+				ids = p.Licenses
+			}
+		}
+		if len(ids) == 0 && isPackageDatabase(name) {
+			ids = []string{`Package-Database`}
+		}
+		if len(ids) == 0 {
+			ids = []string{`Unknown`}
+			f.Unaccounted = true
+			r.Failed = true
+		}
+		for _, id := range ids {
+			counts[f.Layer][id]++
+		}
+		r.Layers[f.Layer].Files++
+		r.Files = append(r.Files, f)
+	}
+
+	for i := range r.Layers {
+		for id, n := range counts[i] {
+			r.Layers[i].Licenses = append(r.Layers[i].Licenses, LicenseCount{id, n})
+		}
+		sort.Slice(r.Layers[i].Licenses, func(a, b int) bool {
+			la, lb := r.Layers[i].Licenses[a], r.Layers[i].Licenses[b]
+			if la.Files != lb.Files {
+				return la.Files > lb.Files
+			}
+			return la.License < lb.License
+		})
+	}
+	return r
+}
+
+// imageLicenseIDs returns the licenses of a file without their markers,
+// leaving out licenses that couldn't be determined.
+func imageLicenseIDs(lics []License) []string {
+	var ids []string
+	for _, lic := range lics {
+		id := licenseID(lic)
+		if id == `` || strings.HasPrefix(id, `Unknown`) || strings.HasPrefix(id, `Error: `) || id == string(tooLarge) {
+			continue
+		}
+		if !contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// writeImageText writes the licenses of each layer and package, then the
+// files that aren't accounted for, or every file if quiet is false.
+func writeImageText(w io.Writer, r ImageReport, quiet bool) {
+	fmt.Fprintf(w, "Image: %s (%d layers)\n", r.Image, len(r.Layers))
+	for i, l := range r.Layers {
+		fmt.Fprintf(w, "\nLayer %d: %s (%d files)\n", i+1, l.Digest, l.Files)
+		for _, c := range l.Licenses {
+			fmt.Fprintf(w, "%46s %d\n", c.License, c.Files)
+		}
+	}
+
+	if len(r.Packages) != 0 {
+		fmt.Fprintf(w, "\nPackages:\n")
+	}
+	for _, p := range r.Packages {
+		declared := strings.Join(p.Licenses, `, `)
+		if declared == `` {
+			declared = `Unknown`
+		}
+		fmt.Fprintf(w, "%-6s%40s %s %s (%d files)\n", p.Manager, declared, p.Name, p.Version, p.Files)
+		if len(p.Detected) != 0 {
+			fmt.Fprintf(w, "%47sdetected in its files: %s\n", "", strings.Join(p.Detected, `, `))
+		}
+	}
+
+	fmt.Fprintln(w)
+	for _, f := range r.Files {
+		if quiet && !f.Unaccounted {
+			continue
+		}
+		errStr, licStr := "", listLicenses(f.Licenses)
+		if f.Unaccounted {
+			errStr, licStr = "Error", "Unknown!"
+		} else if len(imageLicenseIDs(f.Licenses)) == 0 && f.Package != `` {
+			licStr = "package " + f.Package
+		} else if len(imageLicenseIDs(f.Licenses)) == 0 {
+			licStr = "package database"
+		}
+		fmt.Fprintf(w, "%-6s%40s %s (layer %d)\n", errStr, licStr, f.Name, f.Layer+1)
+	}
+}
+
+func writeImageJSON(w io.Writer, r ImageReport, quiet bool) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(``, `  `)
+	enc.Encode(r)
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// layerEntry is an entry of a layer archive written by writeLayer.
+type layerEntry struct {
+	Name     string
+	Type     byte // tar.TypeReg if unset.
+	Body     string
+	Linkname string
+}
+
+// writeLayer writes the entries to a tar archive in dir, and returns it as
+// the layer with the given index.
+func writeLayer(t *testing.T, dir string, index int, entries []layerEntry) imageLayer {
+	name := filepath.Join(dir, `layer`+string('0'+rune(index))+`.tar`)
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := tar.NewWriter(f)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.Name, Typeflag: e.Type, Linkname: e.Linkname, Mode: 0644, Size: int64(len(e.Body))}
+		if hdr.Typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			hdr.Size = 0
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			hdr.Mode = 0755
+		}
+		if err := w.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Size != 0 {
+			if _, err := w.Write([]byte(e.Body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return imageLayer{Digest: name, Path: name}
+}
+
+// unpackedFiles returns the content of each regular file under rootfs, by
+// its slash-separated path.
+func unpackedFiles(t *testing.T, rootfs string) map[string]string {
+	files := make(map[string]string)
+	err := filepath.Walk(rootfs, func(name string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(rootfs, name)
+		files[filepath.ToSlash(rel)] = string(b)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestApplyLayer(t *testing.T) {
+	tests := []struct {
+		name   string
+		layers [][]layerEntry
+		files  map[string]string
+		owners map[string]int
+	}{
+		{
+			name: "upper layers replace files",
+			layers: [][]layerEntry{
+				{{Name: `etc/`, Type: tar.TypeDir}, {Name: `etc/a`, Body: `one`}, {Name: `etc/b`, Body: `two`}},
+				{{Name: `etc/a`, Body: `three`}},
+			},
+			files:  map[string]string{`etc/a`: `three`, `etc/b`: `two`},
+			owners: map[string]int{`etc/a`: 1, `etc/b`: 0},
+		},
+		{
+			name: "whiteout deletes a file",
+			layers: [][]layerEntry{
+				{{Name: `etc/a`, Body: `one`}, {Name: `etc/b`, Body: `two`}},
+				{{Name: `etc/.wh.a`}},
+			},
+			files:  map[string]string{`etc/b`: `two`},
+			owners: map[string]int{`etc/b`: 0},
+		},
+		{
+			name: "whiteout deletes a directory",
+			layers: [][]layerEntry{
+				{{Name: `usr/lib/a`, Body: `one`}, {Name: `usr/lib/x/b`, Body: `two`}, {Name: `usr/libc`, Body: `three`}},
+				{{Name: `./usr/.wh.lib`}},
+			},
+			files:  map[string]string{`usr/libc`: `three`},
+			owners: map[string]int{`usr/libc`: 0},
+		},
+		{
+			name: "whiteout of a missing file",
+			layers: [][]layerEntry{
+				{{Name: `a`, Body: `one`}},
+				{{Name: `.wh.b`}},
+			},
+			files:  map[string]string{`a`: `one`},
+			owners: map[string]int{`a`: 0},
+		},
+		{
+			name: "deleted file added back",
+			layers: [][]layerEntry{
+				{{Name: `a`, Body: `one`}},
+				{{Name: `.wh.a`}},
+				{{Name: `a`, Body: `two`}},
+			},
+			files:  map[string]string{`a`: `two`},
+			owners: map[string]int{`a`: 2},
+		},
+		{
+			name: "opaque whiteout keeps the files of its own layer",
+			layers: [][]layerEntry{
+				{{Name: `opt/a`, Body: `one`}, {Name: `opt/x/b`, Body: `two`}, {Name: `opt2/c`, Body: `three`}},
+				{{Name: `opt/d`, Body: `four`}, {Name: `opt/.wh..wh..opq`}, {Name: `opt/e`, Body: `five`}},
+			},
+			files:  map[string]string{`opt/d`: `four`, `opt/e`: `five`, `opt2/c`: `three`},
+			owners: map[string]int{`opt/d`: 1, `opt/e`: 1, `opt2/c`: 0},
+		},
+		{
+			name: "opaque whiteout at the root",
+			layers: [][]layerEntry{
+				{{Name: `a`, Body: `one`}, {Name: `x/b`, Body: `two`}},
+				{{Name: `.wh..wh..opq`}, {Name: `c`, Body: `three`}},
+			},
+			files:  map[string]string{`c`: `three`},
+			owners: map[string]int{`c`: 1},
+		},
+		{
+			name: "directory replaces a file",
+			layers: [][]layerEntry{
+				{{Name: `a`, Body: `one`}},
+				{{Name: `a/`, Type: tar.TypeDir}, {Name: `a/b`, Body: `two`}},
+			},
+			files:  map[string]string{`a/b`: `two`},
+			owners: map[string]int{`a/b`: 1},
+		},
+		{
+			name: "file in a directory that was a file",
+			layers: [][]layerEntry{
+				{{Name: `a`, Body: `one`}},
+				{{Name: `a/b`, Body: `two`}},
+			},
+			files:  map[string]string{`a/b`: `two`},
+			owners: map[string]int{`a/b`: 1},
+		},
+		{
+			name: "directory in a directory that was a file",
+			layers: [][]layerEntry{
+				{{Name: `a`, Body: `one`}},
+				{{Name: `a/b/`, Type: tar.TypeDir}},
+			},
+			files:  map[string]string{},
+			owners: map[string]int{},
+		},
+		{
+			name: "file replaces a directory",
+			layers: [][]layerEntry{
+				{{Name: `a/b`, Body: `one`}, {Name: `a/c/d`, Body: `two`}},
+				{{Name: `a`, Body: `three`}},
+			},
+			files:  map[string]string{`a`: `three`},
+			owners: map[string]int{`a`: 1},
+		},
+		{
+			name: "symbolic link replaces a file",
+			layers: [][]layerEntry{
+				{{Name: `a`, Body: `one`}, {Name: `b`, Body: `two`}},
+				{{Name: `a`, Type: tar.TypeSymlink, Linkname: `b`}},
+			},
+			files:  map[string]string{`b`: `two`},
+			owners: map[string]int{`b`: 0},
+		},
+		{
+			name: "hard links copy their target",
+			layers: [][]layerEntry{
+				{{Name: `a`, Body: `one`}},
+				{{Name: `b`, Type: tar.TypeLink, Linkname: `a`}, {Name: `c`, Type: tar.TypeLink, Linkname: `missing`}},
+			},
+			files:  map[string]string{`a`: `one`, `b`: `one`},
+			owners: map[string]int{`a`: 0, `b`: 1},
+		},
+		{
+			name: "paths can't escape the filesystem",
+			layers: [][]layerEntry{
+				{{Name: `../../a`, Body: `one`}, {Name: `/b/../c`, Body: `two`}},
+				{{Name: `../.wh.c`}},
+			},
+			files:  map[string]string{`a`: `one`},
+			owners: map[string]int{`a`: 0},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir(``, `weasel-image-`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			rootfs := filepath.Join(dir, `rootfs`)
+			if err := os.Mkdir(rootfs, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			owners := make(map[string]int)
+			for i, entries := range test.layers {
+				if err := applyLayer(writeLayer(t, dir, i, entries), i, rootfs, owners); err != nil {
+					t.Fatalf("layer %d: %v", i, err)
+				}
+			}
+			if files := unpackedFiles(t, rootfs); !reflect.DeepEqual(files, test.files) {
+				t.Errorf("got files %v, want %v", files, test.files)
+			}
+			if !reflect.DeepEqual(owners, test.owners) {
+				t.Errorf("got owners %v, want %v", owners, test.owners)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The package databases read from an image's filesystem.
+const (
+	dpkgStatusFile = `var/lib/dpkg/status`
+	dpkgStatusDir  = `var/lib/dpkg/status.d` // Used by distroless images.
+	dpkgInfoDir    = `var/lib/dpkg/info`
+	dpkgDocDir     = `usr/share/doc`
+	apkInstalled   = `lib/apk/db/installed`
+)
+
+// PackageReport is a package installed in an image.
+type PackageReport struct {
+	Manager string `json:"manager"` // "dpkg" or "apk".
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Licenses are the licenses the package declares: the `L:` field of apk,
+	// or those of its Debian copyright file.
+	Licenses []string `json:"licenses"`
+	// Detected are the licenses found in the package's own files.
+	Detected []string `json:"detected"`
+	Files    int      `json:"files"`
+}
+
+// imagePackage is an installed package with the files it owns.
+type imagePackage struct {
+	PackageReport
+	Files []string
+}
+
+// imagePackages reads the dpkg and apk databases in rootfs, if there are
+// any. files are the classified files of the image, by which the licenses
+// of each package's files are found.
+func imagePackages(rootfs string, files map[string][]License) ([]*imagePackage, error) {
+	dpkg, err := dpkgPackages(rootfs, files)
+	if err != nil {
+		return nil, err
+	}
+	apk, err := apkPackages(rootfs)
+	if err != nil {
+		return nil, err
+	}
+	packages := append(dpkg, apk...)
+
+	for _, p := range packages {
+		for _, name := range p.Files {
+			lics, ok := files[name]
+			if !ok {
+				continue
+			}
+			p.PackageReport.Files++
+			for _, id := range imageLicenseIDs(lics) {
+				if !contains(p.Detected, id) {
+					p.Detected = append(p.Detected, id)
+				}
+			}
+		}
+		sort.Strings(p.Detected)
+		if p.Licenses == nil {
+			p.Licenses = []string{}
+		}
+		if p.Detected == nil {
+			p.Detected = []string{}
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Manager != packages[j].Manager {
+			return packages[i].Manager < packages[j].Manager
+		}
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// dpkgPackages reads the installed packages of dpkg, the files each owns
+// from its `.list` file, and its licenses from its copyright file.
+func dpkgPackages(rootfs string, files map[string][]License) ([]*imagePackage, error) {
+	statusFiles := []string{filepath.Join(rootfs, filepath.FromSlash(dpkgStatusFile))}
+	if entries, err := ioutil.ReadDir(filepath.Join(rootfs, filepath.FromSlash(dpkgStatusDir))); err == nil {
+		for _, entry := range entries {
+			statusFiles = append(statusFiles, filepath.Join(rootfs, filepath.FromSlash(dpkgStatusDir), entry.Name()))
+		}
+	}
+
+	var packages []*imagePackage
+	for _, statusFile := range statusFiles {
+		f, err := os.Open(statusFile)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		paragraphs, err := parseDep5(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Malformed %s: %v", statusFile, err)
+		}
+
+		for _, para := range paragraphs {
+			name := para.Field(`Package`)
+			if name == `` || (para.Field(`Status`) != `` && !strings.HasSuffix(para.Field(`Status`), ` installed`)) {
+				continue
+			}
+			p := &imagePackage{PackageReport: PackageReport{Manager: `dpkg`, Name: name, Version: para.Field(`Version`)}}
+
+			for _, list := range []string{name + `.list`, name + `:` + para.Field(`Architecture`) + `.list`} {
+				b, err := ioutil.ReadFile(filepath.Join(rootfs, filepath.FromSlash(dpkgInfoDir), list))
+				if err != nil {
+					continue
+				}
+				for _, line := range strings.Split(string(b), "\n") {
+					if line = imagePath(strings.TrimSpace(line)); line != `` {
+						p.Files = append(p.Files, line)
+					}
+				}
+			}
+
+			copyright := path.Join(dpkgDocDir, name, `copyright`)
+			p.Licenses = dpkgLicenses(filepath.Join(rootfs, filepath.FromSlash(copyright)))
+			if len(p.Licenses) == 0 {
+				p.Licenses = imageLicenseIDs(files[copyright])
+			}
+			packages = append(packages, p)
+		}
+	}
+	return packages, nil
+}
+
+// dpkgLicenses returns the licenses of a Debian copyright file in the
+// machine-readable format, or none if it is in another format.
+func dpkgLicenses(name string) []string {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	paragraphs, err := parseDep5(f)
+	if err != nil || len(paragraphs) == 0 || paragraphs[0].Field(`Format`) == `` {
+		return nil
+	}
+	var licenses []string
+	for _, p := range paragraphs {
+		if p.Field(`Files`) == `` {
+			continue
+		}
+		if lic := string(dep5License(p.Field(`License`))); lic != `` && !contains(licenses, lic) {
+			licenses = append(licenses, lic)
+		}
+	}
+	sort.Strings(licenses)
+	return licenses
+}
+
+// isPackageDatabase reports whether name is part of the dpkg or apk database,
+// which belongs to no package.
+func isPackageDatabase(name string) bool {
+	return name == dpkgStatusFile || strings.HasPrefix(name, dpkgStatusDir+`/`) || strings.HasPrefix(name, dpkgInfoDir+`/`) || strings.HasPrefix(name, path.Dir(apkInstalled)+`/`)
+}
+
+// apkPackages reads the installed packages of apk, with their licenses and
+// files.
+func apkPackages(rootfs string) ([]*imagePackage, error) {
+	f, err := os.Open(filepath.Join(rootfs, filepath.FromSlash(apkInstalled)))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Each package is a paragraph of "<key>:<value>" lines. `F:` names a
+	// directory, and the `R:` lines after it its files.
+	var packages []*imagePackage
+	var p *imagePackage
+	var dir string
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1024*1024)
+	for s.Scan() {
+		line := s.Text()
+		if line == `` {
+			p = nil
+			continue
+		}
+		if len(line) < 2 || line[1] != ':' {
+			continue
+		}
+		if p == nil {
+			p = &imagePackage{PackageReport: PackageReport{Manager: `apk`}}
+			packages = append(packages, p)
+		}
+		value := line[2:]
+		switch line[0] {
+		case 'P':
+			p.Name = value
+		case 'V':
+			p.Version = value
+		case 'L':
+			if value != `` {
+				p.Licenses = []string{value}
+			}
+		case 'F':
+			dir = value
+		case 'R':
+			if name := imagePath(path.Join(dir, value)); name != `` {
+				p.Files = append(p.Files, name)
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("Cannot read %s: %v", apkInstalled, err)
+	}
+	return packages, nil
+}
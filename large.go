@@ -0,0 +1,178 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// tooLarge is the result for a large file in which no license was found in
+// the parts that were classified, or that wasn't classified at all. It fails
+// the check, unless the file inherits a license from a LICENSE file.
+const tooLarge = License(`Too-Large`)
+
+// LargeFiles is how files too large to classify whole are classified.
+type LargeFiles struct {
+	// Limit is the size in bytes above which a file is large.
+	Limit int64 `json:"limit"`
+	// Mode is "window" to classify the first and last Window bytes of large
+	// files, "sample" to classify Samples chunks of Window bytes spread
+	// evenly through them, or "skip" not to classify them.
+	Mode    string `json:"mode"`
+	Window  int64  `json:"window"`
+	Samples int    `json:"samples"`
+}
+
+var defaultLargeFiles = LargeFiles{
+	Limit:   2 * 1024 * 1024,
+	Mode:    `window`,
+	Window:  64 * 1024,
+	Samples: 8,
+}
+
+func (l LargeFiles) validate() error {
+	switch {
+	case l.Mode != `window` && l.Mode != `sample` && l.Mode != `skip`:
+		return fmt.Errorf("large.mode must be window, sample or skip, not %q", l.Mode)
+	case l.Limit < 1 || l.Window < 1:
+		return fmt.Errorf("large.limit and large.window must be positive")
+	case l.Mode == `sample` && l.Samples < 1:
+		return fmt.Errorf("large.samples must be positive")
+	}
+	return nil
+}
+
+// chunks returns the offsets of the chunks of Window bytes that are
+// classified in a large file of the given size.
+func (l LargeFiles) chunks(size int64) []int64 {
+	switch {
+	case l.Mode == `skip`:
+		return nil
+	case size <= l.Window:
+		return []int64{0}
+	case l.Mode == `window`:
+		return []int64{0, size - l.Window}
+	case l.Samples == 1:
+		return []int64{0}
+	}
+	var offsets []int64
+	for i := 0; i < l.Samples; i++ {
+		offsets = append(offsets, int64(i)*(size-l.Window)/int64(l.Samples-1))
+	}
+	return offsets
+}
+
+// largeMatches classifies the chunks of large content chosen by config, and
+// returns the licenses found in any of them, or tooLarge if there are none.
+func largeMatches(name string, content io.ReaderAt, size int64) ([]LicenseMatch, error) {
+	return classifierFor(name).largeMatches(name, content, size)
+}
+
+// largeMatches is largeMatches, with the classifier state c.
+func (c classifierState) largeMatches(name string, content io.ReaderAt, size int64) ([]LicenseMatch, error) {
+	var matches []LicenseMatch
+	for _, offset := range c.large.chunks(size) {
+		n := inFlight.acquire(c.large.Window)
+		chunk := make([]byte, c.large.Window)
+		read, err := content.ReadAt(chunk, offset)
+		if err != nil && err != io.EOF {
+			inFlight.release(n)
+			return nil, fmt.Errorf("Unable to read %s at %d: %v", name, offset, err)
+		}
+		found := c.identifyMatches(name, string(chunk[:read]))
+		inFlight.release(n)
+
+		for _, m := range found {
+			if !containsMatch(matches, m.License) {
+				matches = append(matches, m)
+			}
+		}
+	}
+	if len(matches) == 0 {
+		return []LicenseMatch{{tooLarge, 0}}, nil
+	}
+	return matches, nil
+}
+
+func containsMatch(matches []LicenseMatch, lic License) bool {
+	for _, m := range matches {
+		if m.License == lic {
+			return true
+		}
+	}
+	return false
+}
+
+// isTooLarge reports whether licenses are only tooLarge.
+func isTooLarge(licenses []License) bool {
+	return len(licenses) == 1 && licenses[0] == tooLarge
+}
+
+// inFlight caps the bytes of file contents held in memory at once by all the
+// workers classifying files.
+var inFlight = &byteSemaphore{limit: 256 * 1024 * 1024}
+
+// byteSemaphore bounds a number of bytes in use at once.
+type byteSemaphore struct {
+	lock  sync.Mutex
+	cond  *sync.Cond
+	limit int64
+	used  int64
+}
+
+// acquire waits until n bytes are available, or all of them if n is more
+// than the limit, and returns the bytes to release.
+func (s *byteSemaphore) acquire(n int64) int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.cond == nil {
+		s.cond = sync.NewCond(&s.lock)
+	}
+	if n > s.limit {
+		n = s.limit
+	}
+	for s.used+n > s.limit {
+		s.cond.Wait()
+	}
+	s.used += n
+	return n
+}
+
+func (s *byteSemaphore) release(n int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.used -= n
+	if s.cond != nil {
+		s.cond.Broadcast()
+	}
+}
+
+// setLimit changes the limit, before any bytes are acquired. The limit must
+// be positive, or nothing could ever be acquired.
+func (s *byteSemaphore) setLimit(limit int64) error {
+	if limit < 1 {
+		return fmt.Errorf("limit must be positive, not %d", limit)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.limit = limit
+	return nil
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import "testing"
+
+func TestByteSemaphoreSetLimit(t *testing.T) {
+	tests := []struct {
+		limit int64
+		ok    bool
+	}{
+		{1, true},
+		{1024, true},
+		{0, false},
+		{-1, false},
+	}
+
+	for _, test := range tests {
+		s := &byteSemaphore{limit: 256}
+		err := s.setLimit(test.limit)
+		if (err == nil) != test.ok {
+			t.Errorf("setLimit(%d) returned %v", test.limit, err)
+		}
+		want := test.limit
+		if !test.ok {
+			want = 256
+		}
+		if s.limit != want {
+			t.Errorf("setLimit(%d) left the limit %d, want %d", test.limit, s.limit, want)
+		}
+	}
+}
@@ -22,25 +22,50 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
 	"runtime/pprof"
-	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 	"unicode"
 
 	"github.com/google/licenseclassifier"
-	"github.com/google/licenseclassifier/stringclassifier"
 )
 
 // Version is the application version number for weasel
 const Version = "0.0.4"
 
+// commands are the subcommands weasel accepts in place of a target
+// directory. Each receives the arguments following its name and returns the
+// exit code.
+var commands = map[string]func(args []string) int{
+	"explain":    explainCommand,
+	"document":   documentCommand,
+	"fix":        fixCommand,
+	"reuse":      reuseCommand,
+	"copyrights": copyrightsCommand,
+	"licenses":   licensesCommand,
+	"dep5":       dep5Command,
+	"history":    historyCommand,
+	"image":      imageCommand,
+	"lsp":        lspCommand,
+	"watch":      watchCommand,
+	"serve":      serveCommand,
+	"classify":   classifyCommand,
+	"check":      checkCommand,
+	"hook":       hookCommand,
+}
+
 func main() {
 	var all bool
 	flag.BoolVar(&all, "a", false, "Print all files and their licenses, not just problematic files.")
@@ -52,6 +77,16 @@ func main() {
 	flag.BoolVar(&profile, "p", false, "Collect and output profiling statistics.")
 	var printVersion bool
 	flag.BoolVar(&printVersion, "v", false, "Print version and exit.")
+	var format string
+	flag.StringVar(&format, "format", "text", "Report format: text or json.")
+	var workers int
+	flag.IntVar(&workers, "j", runtime.GOMAXPROCS(0), "Number of files to classify at once.")
+	var timeout time.Duration
+	flag.DurationVar(&timeout, "timeout", time.Minute, "Longest time to spend classifying a single file, or 0 for no limit.")
+	var failFast bool
+	flag.BoolVar(&failFast, "fail-fast", false, "Stop at the first file that fails the check.")
+	var maxInFlight int64
+	flag.Int64Var(&maxInFlight, "max-inflight", inFlight.limit, "Most bytes of file contents to hold in memory at once.")
 	_ = flag.Bool("q", true, "Only print problematic files. DEPRECATED: as of v0.0.4 this flag is deprecated and does nothing - just use -a or its absence.")
 	flag.Parse()
 	quiet := !all
@@ -67,6 +102,24 @@ func main() {
 		exit(0)
 	}
 
+	if len(args) > 0 {
+		if command, ok := commands[args[0]]; ok {
+			exit(command(args[1:]))
+		}
+	}
+
+	writeReport, ok := reportFormats[format]
+	if !ok {
+		fmt.Println("Unknown report format: " + format)
+		exit(1)
+		return
+	}
+	if err := inFlight.setLimit(maxInFlight); err != nil {
+		fmt.Println("Bad -max-inflight: " + err.Error())
+		exit(1)
+		return
+	}
+
 	if profile {
 		pf, err := os.Create("weasel.pprof")
 		if err != nil {
@@ -123,28 +176,12 @@ func main() {
 	}
 
 	if cd == `` {
-		/* Find the .git directory. */
-		p, err := os.Getwd()
+		var err error
+		cd, err = findRoot()
 		if err != nil {
 			fmt.Fprintln(w, "Unable to get working directory: "+err.Error())
 			return
 		}
-		p = strings.TrimRight(p, `/`)
-
-		patience := 10000 /* patience exists in case there are loops or other excessively long paths. */
-		for p != `` && patience != 0 {
-			if fi, err := os.Stat(filepath.Join(p, ".git")); err == nil && fi.IsDir() {
-				cd = p
-				break
-			}
-			p, _ = filepath.Split(p)
-			p = strings.TrimRight(p, `/`)
-
-			patience--
-		}
-	}
-	if !quiet {
-		fmt.Fprintln(w, "In directory: "+cd)
 	}
 	if cd != `` {
 		err := os.Chdir(cd)
@@ -173,13 +210,141 @@ func main() {
 		}
 	}
 
-	loadOverrides()
-	recordDocumentedLicenses()
+	if err := loadProject(); err != nil {
+		fmt.Fprintln(w, err.Error())
+		exit(1)
+		return
+	}
+
+	// The first interrupt stops the scan, and a second one weasel.
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		signal.Stop(interrupt)
+		cancel()
+	}()
+
+	// Show progress on a terminal, where it can be overwritten.
+	start := time.Now()
+	progress := &scanProgress{}
+	stopProgress, progressDone := make(chan struct{}), make(chan struct{})
+	if isTerminal(os.Stderr) {
+		go showProgress(os.Stderr, progress, stopProgress, progressDone)
+	} else {
+		close(progressDone)
+	}
+
+	files, copyrights, err := scanWith(ctx, subdir, scanOptions{Workers: workers, Timeout: timeout, FailFast: failFast, Progress: progress})
+	close(stopProgress)
+	<-progressDone
+	if err == context.Canceled {
+		fmt.Fprintln(w, "Interrupted")
+		exit(1)
+		return
+	}
+	if err != nil && err != errFailFast {
+		fmt.Fprintln(w, err)
+		exit(1)
+		return
+	}
+
+	report := buildReport(cd, files, copyrights)
+	report.Summary = summarize(report, progress, time.Since(start))
+	writeReport(w, report, quiet)
+	if err == errFailFast {
+		fmt.Fprintln(w, err.Error())
+	}
+
+	if profile {
+		pprof.StopCPUProfile()
+	}
+	if report.Failed {
+		exit(1)
+	}
+	exit(0)
+}
+
+// scanOptions controls how scanWith classifies files.
+type scanOptions struct {
+	// Workers is how many files are classified at once, or GOMAXPROCS if it
+	// is 0.
+	Workers int
+	// Timeout is the longest a single file may take to classify, or no limit
+	// if it is 0. Files that take longer fail with an error.
+	Timeout time.Duration
+	// FailFast stops the scan at the first file found to fail the check.
+	FailFast bool
+	// Progress, if set, is updated as files are found and classified.
+	Progress *scanProgress
+}
+
+// errFailFast is returned by scanWith, with the files classified so far, when
+// it stops at a failing file.
+var errFailFast = errors.New("Stopped at the first failing file")
+
+// scan classifies every file under subdir that is not ignored, applying
+// overrides, inheritance from LICENSE files, and the LICENSE documentation
+// check. Files that fail the check have a `!` appended to their licenses.
+// The copyright statements found in each file are also returned.
+func scan(subdir string) (map[string][]License, map[string][]Copyright, error) {
+	return scanWith(context.Background(), subdir, scanOptions{})
+}
+
+// scanFile is a file found by the walker in scanWith, for a worker to
+// classify.
+type scanFile struct {
+	name string
+	info os.FileInfo
+}
+
+// scanWith is scan, classifying files in a pool of workers fed by the walker
+// as opts says, until ctx is cancelled.
+func scanWith(ctx context.Context, subdir string, opts scanOptions) (map[string][]License, map[string][]Copyright, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	progress := opts.Progress
+	if progress == nil {
+		progress = &scanProgress{}
+	}
 
 	files := make(map[string][]License)
-	var wg sync.WaitGroup
+	copyrights := make(map[string][]Copyright)
 	var filesLock sync.Mutex
-	throttle := make(chan struct{}, 32)
+	failed := false
+	found := make(chan scanFile, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range found {
+				if ctx.Err() != nil {
+					continue // Drain the files already found.
+				}
+				licenses, statements := classifyFile(f.name, f.info, opts.Timeout)
+				atomic.AddInt64(&progress.Classified, 1)
+				progress.Current.Store(f.name)
+
+				filesLock.Lock()
+				files[f.name] = licenses
+				if len(statements) != 0 {
+					copyrights[f.name] = statements
+				}
+				if opts.FailFast && fails(f.name, licenses) {
+					failed = true
+					cancel()
+				}
+				filesLock.Unlock()
+			}
+		}()
+	}
+
 	err := filepath.Walk(subdir, func(name string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -190,6 +355,9 @@ func main() {
 		}
 
 		if Ignored(name) {
+			if !info.IsDir() {
+				atomic.AddInt64(&progress.Ignored, 1)
+			}
 			return nil
 		}
 
@@ -201,207 +369,503 @@ func main() {
 			return nil
 		}
 
-		if info.Size() == 0 {
-			filesLock.Lock()
-			defer filesLock.Unlock()
-			files[name] = append(files[name], License("Empty"))
+		if isReuseMetadata(name) || isCustomLicenseText(name) {
 			return nil
 		}
 
-		wg.Add(1)
-		go func(name string) {
-			throttle <- struct{}{}
-			defer func() { <-throttle }()
-			defer wg.Done()
-			licenses, err := fileLicenses(name)
-			if err != nil {
-				licenses = []License{License("Error: " + err.Error() + "!")}
-			}
-
-			filesLock.Lock()
-			defer filesLock.Unlock()
-			files[name] = append(files[name], override[name]...)
-			files[name] = append(files[name], licenses...)
-			files[name] = Collide(Uniq(files[name]))
-		}(name)
-		return nil
+		atomic.AddInt64(&progress.Discovered, 1)
+		if info.Size() > projectFor(name).Config.Large.Limit {
+			atomic.AddInt64(&progress.Large, 1)
+		}
+		select {
+		case found <- scanFile{name, info}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	})
+	close(found)
 	wg.Wait()
-	if err != nil {
-		fmt.Fprintln(w, err)
-		return
+	if failed {
+		err = errFailFast
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	resolveLicenses(files, func(licPath string) []License { return files[licPath] })
+	return files, copyrights, err
+}
+
+// classifyFile classifies a file and finds its copyright statements, giving
+// up after timeout, unless it is 0. Classification can't be interrupted, so
+// a file that times out is still read in the background.
+func classifyFile(name string, info os.FileInfo, timeout time.Duration) ([]License, []Copyright) {
+	if timeout <= 0 {
+		return classifyWithCopyrights(name, info)
+	}
+
+	type result struct {
+		licenses   []License
+		copyrights []Copyright
+	}
+	done := make(chan result, 1)
+	go func() {
+		licenses, copyrights := classifyWithCopyrights(name, info)
+		done <- result{licenses, copyrights}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.licenses, r.copyrights
+	case <-timer.C:
+		return []License{License(fmt.Sprintf("Error: timed out after %v!", timeout))}, nil
+	}
+}
+
+// fails reports whether a file with licenses fails the check, if that can be
+// known before the files it may inherit from are classified.
+func fails(name string, licenses []License) bool {
+	if len(licenses) == 0 {
+		return false
 	}
+	lics := append([]License(nil), licenses...)
+	markUndocumented(name, lics)
+	_, ignore, undoc := verdict(lics)
+	return undoc && !ignore
+}
 
-forUnknownFiles:
+// resolveLicenses applies resolveFile to classified files, inheriting from
+// the LICENSE files for which lookup returns licenses.
+func resolveLicenses(files map[string][]License, lookup func(licPath string) []License) {
+	resolved := make(map[string][]License, len(files))
 	for name, licenses := range files {
-		if len(licenses) == 0 {
-			parts := strings.Split(name, `/`)
-			for i := len(parts) - 1; i > 0; i-- {
-				for _, licName := range []string{`LICENSE`, `LICENCE`, `LICENSE.md`, `LICENCE.md`, `LICENSE.txt`, `LICENCE.txt`, `COPYING`, `COPYING.md`, `COPYING.txt`} {
-					licPath := strings.Join(parts[:i], `/`) + `/` + licName
-					if len(files[licPath]) != 0 {
-						for _, license := range files[licPath] {
-							if license != License(`Docs`) {
-								files[name] = append(files[name], License(string(license)+"~"))
-							}
-						}
-						continue forUnknownFiles
-					}
-				}
-			}
+		resolved[name] = resolveFile(name, licenses, lookup, filekind)
+	}
+	for name, licenses := range resolved {
+		files[name] = licenses
+	}
+}
+
+// resolveFile applies inheritance from the LICENSE files for which lookup
+// returns licenses and the LICENSE documentation check to the classified
+// licenses of a file. A file that is still too large fails as such, and one
+// with no licenses is given its kind, if it has one.
+func resolveFile(name string, licenses []License, lookup func(licPath string) []License, kind func(name string) string) []License {
+	lics := append([]License(nil), licenses...)
+	if len(lics) == 0 || isTooLarge(lics) {
+		if _, inherited := inherit(name, lookup); len(inherited) != 0 {
+			lics = inherited
 		}
 	}
+	if isTooLarge(lics) {
+		return []License{tooLarge + `!`}
+	}
+	if len(lics) != 0 {
+		markUndocumented(name, lics)
+		return lics
+	}
+	if k := kind(name); k != `` {
+		return []License{License(k)}
+	}
+	return nil
+}
 
-	for name, licenses := range files {
-		if len(licenses) != 0 {
-			if len(licenses) > 1 || (licenses[0] != License(`Apache-2.0`) && licenses[0] != License(`Docs`) && licenses[0] != License(`Empty`) && licenses[0] != License(`Ignore`)) {
-				if !documented.Documents(name) {
-					for i, lic := range licenses {
-						if lic != License(`Apache-2.0`) && lic != License(`Docs`) && lic != License(`Empty`) && lic != License(`Ignore`) {
-							licenses[i] = License(string(licenses[i]) + `!`)
-						}
-					}
-				}
+// classifyAll runs classify on each of names in a pool of GOMAXPROCS
+// workers, and returns the licenses of each.
+func classifyAll(names []string, classify func(name string) []License) map[string][]License {
+	files := make(map[string][]License)
+	var filesLock sync.Mutex
+	queue := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range queue {
+				licenses := classify(name)
+
+				filesLock.Lock()
+				files[name] = licenses
+				filesLock.Unlock()
 			}
+		}()
+	}
+	for _, name := range names {
+		queue <- name
+	}
+	close(queue)
+	wg.Wait()
+	return files
+}
+
+// findRoot searches upward from the working directory for a directory
+// containing a .git folder, which is taken to be the root of the project.
+func findRoot() (string, error) {
+	p, err := os.Getwd()
+	if err != nil {
+		return ``, err
+	}
+	p = strings.TrimRight(p, `/`)
+
+	patience := 10000 /* patience exists in case there are loops or other excessively long paths. */
+	for p != `` && patience != 0 {
+		if fi, err := os.Stat(filepath.Join(p, ".git")); err == nil && fi.IsDir() {
+			return p, nil
 		}
+		p, _ = filepath.Split(p)
+		p = strings.TrimRight(p, `/`)
+
+		patience--
 	}
+	return ``, nil
+}
 
-	for name, licenses := range files {
-		if len(licenses) == 0 {
-			kind := filekind(name)
-			if kind != `` {
-				files[name] = []License{License(kind)}
-			}
+// loadProject reads the configuration, custom licenses, REUSE annotations,
+// overrides, debian/copyright and LICENSE file of the project in the working
+// directory, and finds the projects nested in it.
+func loadProject() error {
+	if err := loadConfig(); err != nil {
+		return err
+	}
+	if err := loadCustomLicenses(); err != nil {
+		return err
+	}
+	if err := loadReuse(); err != nil {
+		return err
+	}
+	tree := walkProjectTree()
+	loadOverrides(tree)
+	if err := loadDebianCopyright(); err != nil {
+		return err
+	}
+	return loadProjects(tree.projectDirs)
+}
+
+// classify determines the licenses of a single file from its contents, or
+// its REUSE `.license` sidecar, any REUSE annotation covering it, and any
+// .dependency_license overrides. Inheritance from LICENSE files and the
+// LICENSE documentation check are applied afterward.
+func classify(name string, info os.FileInfo) []License {
+	return classifyWith(name, info.Size(), fileLicenses)
+}
+
+// classifyWithCopyrights is classify, also returning the copyright statements
+// of the file, as copyrightsOf does, from the same read of it.
+func classifyWithCopyrights(name string, info os.FileInfo) ([]License, []Copyright) {
+	var found []Copyright
+	licenses := classifyWith(name, info.Size(), func(source string) ([]License, error) {
+		lics, copyrights, err := fileLicensesAndCopyrights(source)
+		found = copyrights
+		return lics, err
+	})
+	// copyrightsWith reads the same source as classifyWith, if any.
+	copyrights := copyrightsWith(name, info.Size(), func(string) ([]Copyright, error) {
+		return found, nil
+	})
+	return licenses, copyrights
+}
+
+// classifyWith classifies a file of the given size as classify does, finding
+// the licenses of its contents, or its sidecar's, with readLicenses.
+func classifyWith(name string, size int64, readLicenses func(source string) ([]License, error)) []License {
+	source, annotation := reuseSource(name), reuseAnnotationFor(name)
+	if size == 0 && source == name && annotation == nil {
+		return []License{License("Empty")}
+	}
+
+	var licenses []License
+	if (size != 0 || source != name) && !annotation.overrides() {
+		var err error
+		licenses, err = readLicenses(source)
+		if err != nil {
+			licenses = []License{License("Error: " + err.Error() + "!")}
 		}
 	}
+	licenses = annotation.licenses(licenses)
 
-	var filenames []string
-	for filename := range files {
-		filenames = append(filenames, filename)
+	// A large file with a license from elsewhere isn't too large.
+	if isTooLarge(licenses) && len(override[name]) != 0 {
+		licenses = nil
 	}
-	sort.Strings(filenames)
+	if len(override[name]) == 0 {
+		licenses = debianLicenses(debianRules, name, licenses)
+	}
+	var lics []License
+	lics = append(lics, override[name]...)
+	lics = append(lics, licenses...)
+	return Collide(Uniq(lics))
+}
 
-	failed := false
-	for _, filename := range filenames {
-		lics := files[filename]
-		ignore := false
-		undoc := false
-		var licStr string
-		if len(lics) == 0 {
-			licStr = "Unknown!"
-			undoc = true
-		} else {
-			licStr = fmt.Sprint(lics[0])
-			ignore = (licStr == `Ignore`)
-			if len(licStr) > 0 && licStr[len(licStr)-1] == '!' {
-				undoc = true
-			}
-			for _, lic := range lics[1:] {
-				if string(lic) == `Ignore` {
-					ignore = true
-				}
-				licStr = licStr + `, ` + fmt.Sprint(lic)
-			}
+// licenseFileNames are the names of files whose licenses are inherited by
+// otherwise unlicensed files in the same directory or below.
+var licenseFileNames = []string{`LICENSE`, `LICENCE`, `LICENSE.md`, `LICENCE.md`, `LICENSE.txt`, `LICENCE.txt`, `COPYING`, `COPYING.md`, `COPYING.txt`}
+
+// inherit finds the closest LICENSE or COPYING file above name for which
+// lookup returns any licenses, and returns its path along with those
+// licenses marked with a `~`. Licenses aren't inherited from outside the
+// nested project name is in, nor, as with the root project, from its own
+// LICENSE file.
+func inherit(name string, lookup func(string) []License) (string, []License) {
+	return inheritIn(projects, name, lookup)
+}
+
+// inheritIn finds the license inherited by name as inherit does, within the
+// nested projects of ps.
+func inheritIn(ps []*Project, name string, lookup func(string) []License) (string, []License) {
+	parts := strings.Split(name, `/`)
+	for i := len(parts) - 1; i > 0; i-- {
+		dir := strings.Join(parts[:i], `/`)
+		if isProjectDirIn(ps, dir) {
+			break
 		}
-		if !ignore {
-			errStr := ""
-			if undoc {
-				errStr = "Error"
-				failed = true
-			}
-			if undoc || !quiet {
-				fmt.Fprintf(w, "%-6s%40s %s\n", errStr, licStr, filename)
+		for _, licName := range licenseFileNames {
+			licPath := dir + `/` + licName
+			if lics := lookup(licPath); len(lics) != 0 {
+				var inherited []License
+				for _, license := range lics {
+					if license != License(`Docs`) {
+						inherited = append(inherited, License(string(license)+"~"))
+					}
+				}
+				return licPath, inherited
 			}
 		}
 	}
-	for _, extra := range documented.Extra() {
-		fmt.Fprintf(w, "%-6s%40s %s\n", "Error", "Extra-License!", extra)
-		failed = true
+	return ``, nil
+}
+
+// accepted reports whether lic isn't a license, and so may appear without
+// being documented in any LICENSE file.
+func accepted(lic License) bool {
+	return lic == License(`Docs`) || lic == License(`Empty`) || lic == License(`Ignore`)
+}
+
+// markUndocumented appends a `!` to each license that must be documented in
+// the LICENSE file of name's project, but is not documented for name. A
+// license documented under a section of the LICENSE file for another license
+// is not documented.
+func markUndocumented(name string, licenses []License) {
+	markUndocumentedIn(projects, name, licenses)
+}
+
+// markUndocumentedIn marks the licenses of name as markUndocumented does,
+// against the project of ps it is in.
+func markUndocumentedIn(ps []*Project, name string, licenses []License) {
+	p := projectIn(ps, name)
+	for i, lic := range licenses {
+		if !p.accepts(lic) && !p.Documented.Covers(p.rel(name), lic) {
+			licenses[i] = License(string(licenses[i]) + `!`)
+		}
 	}
+}
 
-	if profile {
-		pprof.StopCPUProfile()
+// verdict renders the final licenses of a file for output, and reports
+// whether the file is ignored and whether it fails the check.
+func verdict(lics []License) (licStr string, ignore bool, undoc bool) {
+	if len(lics) == 0 {
+		return "Unknown!", false, true
 	}
-	if failed {
-		exit(1)
+	licStr = fmt.Sprint(lics[0])
+	ignore = (licStr == `Ignore`)
+	if len(licStr) > 0 && licStr[len(licStr)-1] == '!' {
+		undoc = true
 	}
-	exit(0)
+	for _, lic := range lics[1:] {
+		if string(lic) == `Ignore` {
+			ignore = true
+		}
+		licStr = licStr + `, ` + fmt.Sprint(lic)
+	}
+	return licStr, ignore, undoc
 }
 
 func fileLicenses(name string) ([]License, error) {
-	spdx, err := spdxLicenses(name)
+	licenses, _, err := readFileLicenses(name, false)
+	return licenses, err
+}
+
+// fileLicensesAndCopyrights returns the licenses of a file, as fileLicenses
+// does, and its copyright statements, as fileCopyrights does, reading it
+// once.
+func fileLicensesAndCopyrights(name string) ([]License, []Copyright, error) {
+	return readFileLicenses(name, true)
+}
+
+// readFileLicenses is fileLicenses, also finding the file's copyright
+// statements if withCopyrights is set, in what was read of it.
+func readFileLicenses(name string, withCopyrights bool) ([]License, []Copyright, error) {
+	head, tail, err := spdxWindows(name)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	windowCopyrights := func() []Copyright {
+		if !withCopyrights {
+			return nil
+		}
+		return windowsCopyrights(head, tail)
 	}
-	if len(spdx) > 0 {
-		return spdx, nil // If they provided an explicit SPDX id, just use that.
+	if spdx := append(spdxLicenseSearch(head), spdxLicenseSearch(tail)...); len(spdx) > 0 {
+		return spdx, windowCopyrights(), nil // If they provided an explicit SPDX id, just use that.
 	}
 
 	fi, err := os.Stat(name)
 	if err != nil {
-		return nil, err
-	}
-	if fi.Size() > 2*1024*1024 {
-		return nil, nil
+		return nil, nil, err
 	}
 
 	f, err := os.Open(name)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer f.Close()
 
-	return identifyLicenses(f)
+	if c := classifierFor(name); fi.Size() > c.large.Limit {
+		matches, err := c.largeMatches(name, f, fi.Size())
+		if err != nil {
+			return nil, nil, err
+		}
+		var licenses []License
+		for _, m := range matches {
+			licenses = append(licenses, m.License)
+		}
+		return licenses, windowCopyrights(), nil
+	}
+	n := inFlight.acquire(fi.Size())
+	defer inFlight.release(n)
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to read all of file: %v", err)
+	}
+	licenses, err := identifyLicenses(name, bytes.NewReader(b))
+	if err != nil || !withCopyrights {
+		return licenses, nil, err
+	}
+	return licenses, parseCopyrights(b), nil
+}
+
+// contentLicenses determines the licenses of content that isn't in a file,
+// such as a blob in git, as fileLicenses does for the file name.
+func contentLicenses(name string, content []byte) ([]License, error) {
+	return classifierFor(name).contentLicenses(name, content)
+}
+
+// contentLicenses is contentLicenses, with the classifier state c.
+func (c classifierState) contentLicenses(name string, content []byte) ([]License, error) {
+	matches, err := c.contentMatches(name, content)
+	if err != nil {
+		return nil, err
+	}
+	var licenses []License
+	for _, m := range matches {
+		licenses = append(licenses, m.License)
+	}
+	return licenses, nil
+}
+
+// contentMatches is contentLicenses, with the confidence of each license.
+func contentMatches(name string, content []byte) ([]LicenseMatch, error) {
+	return classifierFor(name).contentMatches(name, content)
+}
+
+// contentMatches is contentMatches, with the classifier state c.
+func (c classifierState) contentMatches(name string, content []byte) ([]LicenseMatch, error) {
+	head, tail, err := readSPDXWindows(name, bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+	if spdx := append(spdxLicenseSearch(head), spdxLicenseSearch(tail)...); len(spdx) > 0 {
+		var matches []LicenseMatch
+		for _, lic := range spdx {
+			matches = append(matches, LicenseMatch{lic, 1})
+		}
+		return matches, nil
+	}
+	if int64(len(content)) > c.large.Limit {
+		return c.largeMatches(name, bytes.NewReader(content), int64(len(content)))
+	}
+	return c.identifyMatches(name, string(content)), nil
 }
 
 func spdxLicenses(name string) ([]License, error) {
-	fi, err := os.Stat(name)
+	head, tail, err := spdxWindows(name)
 	if err != nil {
 		return nil, err
 	}
+	return append(spdxLicenseSearch(head), spdxLicenseSearch(tail)...), nil
+}
+
+// spdxWindows returns the portions of a file that are searched for SPDX
+// identifiers. Small files are returned whole as the head, with no tail.
+func spdxWindows(name string) (head []byte, tail []byte, err error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	f, err := os.Open(name)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer f.Close()
 
+	return readSPDXWindows(name, f, fi.Size())
+}
+
+// readSPDXWindows returns the portions of content of the given size that are
+// searched for SPDX identifiers, as spdxWindows does for a file.
+func readSPDXWindows(name string, content io.ReaderAt, size int64) (head []byte, tail []byte, err error) {
 	const maxBuffer = 2 * 1024 // Only check the first and last 10k of the file, for performance.
 
-	if fi.Size() < maxBuffer {
-		b, err := ioutil.ReadAll(f)
+	if size < maxBuffer {
+		b, err := ioutil.ReadAll(io.NewSectionReader(content, 0, size))
 		if err != nil {
-			return nil, fmt.Errorf("Unable to read all of file %s: %v", name, err)
+			return nil, nil, fmt.Errorf("Unable to read all of file %s: %v", name, err)
 		}
-		return spdxLicenseSearch(b), nil
+		return b, nil, nil
 	}
 
-	b := make([]byte, maxBuffer)
-	n, err := f.Read(b)
+	head = make([]byte, maxBuffer)
+	n, err := content.ReadAt(head, 0)
 	if err != nil && err != io.EOF {
-		return nil, fmt.Errorf("Unable to read top of %s: %v", name, err)
+		return nil, nil, fmt.Errorf("Unable to read top of %s: %v", name, err)
 	}
-	topLicenses := spdxLicenseSearch(b[:n])
+	head = head[:n]
 
-	tail := fi.Size() - maxBuffer
-	if tail < maxBuffer {
-		tail = maxBuffer
+	tailOffset := size - maxBuffer
+	if tailOffset < maxBuffer {
+		tailOffset = maxBuffer
 	}
-	n, err = f.ReadAt(b, tail)
+	tail = make([]byte, maxBuffer)
+	n, err = content.ReadAt(tail, tailOffset)
 	if err != nil && err != io.EOF {
-		return nil, fmt.Errorf("Unable to read tail of %s: %v", name, err)
+		return nil, nil, fmt.Errorf("Unable to read tail of %s: %v", name, err)
 	}
-	tailLicenses := spdxLicenseSearch(b[:n])
-	return append(topLicenses, tailLicenses...), nil
+	return head, tail[:n], nil
 }
 
 func spdxLicenseSearch(b []byte) []License {
+	var licenses []License
+	for _, tag := range spdxTags(b) {
+		licenses = append(licenses, tag.License)
+	}
+	return licenses
+}
+
+// spdxTag is a single SPDX-License-Identifier line found in a file.
+type spdxTag struct {
+	Line    int // Line number within the searched window, starting at 1.
+	Text    string
+	License License
+}
+
+func spdxTags(b []byte) []spdxTag {
 	spdxShort := []byte("SPDX-License-Identifier:")
 
-	var licenses []License
+	var tags []spdxTag
 	lines := bytes.Split(b, []byte("\n"))
 forLines:
-	for _, line := range lines {
+	for i, line := range lines {
 		idx := bytes.Index(line, spdxShort)
 		if idx >= 0 {
 			prefix := line[:idx]
@@ -420,43 +884,146 @@ forLines:
 				continue forLines
 			}
 			suffix := bytes.Trim(line[suffixIdx:], ` `)
-			licenses = append(licenses, License(suffix))
+			tags = append(tags, spdxTag{i + 1, string(bytes.TrimSpace(line)), License(suffix)})
 		}
 	}
-	return licenses
+	return tags
 }
 
 var classifier *licenseclassifier.License
 
+// licenseArchive is the archive of licenses the classifier was built from:
+// LicenseDBContents, plus any custom licenses.
+var licenseArchive = LicenseDBContents
+
+// customLicenseTexts are the original texts of the custom licenses the
+// classifier was built with, by id.
+var customLicenseTexts map[string]string
+
+// variantClassifier finds the nearest license to a license file that matches
+// none, among the licenses the classifier was built from.
+var variantClassifier = newNearClassifier(LicenseDBContents)
+
+// classifierState is the state that classifying content depends on and
+// loading a project may change: the classifier, its archive, the texts of
+// licenses and the classifier of variants, which custom licenses replace,
+// and the configuration of large files. None of it is changed once taken,
+// so a state can be used while another project is loaded.
+type classifierState struct {
+	classifier *licenseclassifier.License
+	archive    []byte
+	texts      map[string]string
+	near       *nearClassifier
+	large      LargeFiles
+}
+
+// currentClassifier returns the classifier state of the loaded project.
+func currentClassifier() classifierState {
+	return classifierState{classifier, licenseArchive, customLicenseTexts, variantClassifier, config.Large}
+}
+
+// classifierFor returns the classifier state for the file name, with the
+// configuration of large files of the project it is in.
+func classifierFor(name string) classifierState {
+	return classifierIn(projects, name)
+}
+
+// classifierIn is classifierFor, for the file name in one of ps.
+func classifierIn(ps []*Project, name string) classifierState {
+	c := currentClassifier()
+	c.large = projectIn(ps, name).Config.Large
+	return c
+}
+
+// setClassifier loads the classifier, its archive, the license texts and the
+// classifier of variants of c. The configuration of large files is the
+// project's, and isn't changed.
+func setClassifier(c classifierState) {
+	classifier, licenseArchive, customLicenseTexts, variantClassifier = c.classifier, c.archive, c.texts, c.near
+}
+
+// builtinClassifier is the classifier state with only the licenses in the
+// database.
+var builtinClassifier classifierState
+
+// classifierThreshold is the lowest confidence at which the classifier
+// reports a match.
+const classifierThreshold = 0.8
+
 func init() {
 	var err error
-	classifier, err = licenseclassifier.New(0.8, licenseclassifier.ArchiveBytes(LicenseDBContents))
+	classifier, err = licenseclassifier.New(classifierThreshold, licenseclassifier.ArchiveBytes(LicenseDBContents))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize classifier: %v\n", err)
 		exit(-1)
 	}
+	builtinClassifier = currentClassifier()
 }
 
-func identifyLicenses(in io.Reader) ([]License, error) {
-	var licenses Licenses
+// identifyLicenses classifies a file's content. Matches that change their
+// license substantively are reported as variants of it, as are near matches
+// for license files, which would otherwise be reported as Unknown.
+func identifyLicenses(name string, in io.Reader) ([]License, error) {
 	b, err := ioutil.ReadAll(in)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to read all of file: %v", err)
 	}
 
-	var matches stringclassifier.Matches
-	if err == nil {
-		matches = classifier.MultipleMatch(string(b), true)
-	} else {
-		return nil, fmt.Errorf("Cannot create classifier: %v\n", err)
+	var licenses Licenses
+	for _, m := range identifyMatches(name, string(b)) {
+		licenses = append(licenses, m.License)
+	}
+	return licenses, nil
+}
+
+// LicenseMatch is a license found in a file, with the classifier's
+// confidence in it, from 0 to 1. Licenses named by SPDX identifiers have a
+// confidence of 1.
+type LicenseMatch struct {
+	License    License `json:"license"`
+	Confidence float64 `json:"confidence"`
+}
+
+// identifyMatches is identifyLicenses, with the confidence of each license.
+func identifyMatches(name string, text string) []LicenseMatch {
+	return currentClassifier().identifyMatches(name, text)
+}
+
+// identifyMatches is identifyMatches, with the classifier state c.
+func (c classifierState) identifyMatches(name string, text string) []LicenseMatch {
+	defer spent(&timings.Classifier, time.Now())
+	var matches []LicenseMatch
+	// Only license files are compared word by word with the licenses they
+	// match, which is too slow for every file, and would take a header for
+	// a variant of the full text.
+	licenseFile := isLicenseFile(name)
+	for _, match := range c.classifier.MultipleMatch(text, true) {
+		if match == nil {
+			continue
+		}
+		lic := License(match.Name)
+		if licenseFile {
+			lic = c.variantLicense(lic, match.Confidence, text)
+		}
+		matches = append(matches, LicenseMatch{lic, match.Confidence})
 	}
+	if len(matches) == 0 && licenseFile {
+		if v, confidence := c.nearestVariant(text); v != nil {
+			matches = append(matches, LicenseMatch{License(strings.TrimSuffix(v.License, `.header`) + variantSuffix), confidence})
+		}
+	}
+	return matches
+}
 
-	for _, match := range matches {
-		if match != nil {
-			licenses = append(licenses, License(match.Name))
+// isLicenseFile reports whether name is one of licenseFileNames.
+func isLicenseFile(name string) bool {
+	base := filepath.Base(name)
+	for _, licName := range licenseFileNames {
+		if base == licName {
+			return true
 		}
 	}
-	return licenses, nil
+	return false
 }
 
 func exit(code int) {
@@ -20,7 +20,10 @@ SPDX-License-Identifier: Apache-2.0
 */
 package main
 
-import "sort"
+import (
+	"sort"
+	"strings"
+)
 
 type License string
 
@@ -36,6 +39,12 @@ func (lics Licenses) Less(i, j int) bool {
 	return lics[i] < lics[j]
 }
 
+// licenseID returns the license id of lic, without any `!` or `~` markers or
+// a `.header` suffix.
+func licenseID(lic License) string {
+	return strings.TrimSuffix(strings.TrimRight(string(lic), `!~`), `.header`)
+}
+
 func Uniq(lics []License) []License {
 	if len(lics) == 0 {
 		return nil
@@ -0,0 +1,146 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Metadata describes the licenses in the database, as written by dbmaker to
+// metadata.json in LicenseDataContents.
+type Metadata struct {
+	ListVersion string                 `json:"licenseListVersion"`
+	Licenses    map[string]LicenseInfo `json:"licenses"`
+}
+
+// LicenseInfo is the metadata of a single license or exception.
+type LicenseInfo struct {
+	Name        string   `json:"name"`
+	OSIApproved bool     `json:"osiApproved,omitempty"`
+	FSFLibre    bool     `json:"fsfLibre,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+	Exception   bool     `json:"exception,omitempty"`
+	SeeAlso     []string `json:"seeAlso,omitempty"`
+}
+
+// licenseTexts are the texts of the licenses in the database. Custom licenses
+// are kept by the classifier state instead, so these never change once read.
+var (
+	licenseTexts     map[string]string
+	licenseMetadata  Metadata
+	licenseTextsOnce sync.Once
+)
+
+// loadLicenseTexts reads the original license texts and their metadata from
+// LicenseDataContents. Unlike LicenseDBContents, which holds the classifier's
+// normalized texts, these are suitable for reproducing in a LICENSE file.
+func loadLicenseTexts() {
+	licenseTexts = make(map[string]string)
+
+	gr, err := gzip.NewReader(bytes.NewReader(LicenseDataContents))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read license texts: %v\n", err)
+		return
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read license texts: %v\n", err)
+			return
+		}
+		b, err := ioutil.ReadAll(tr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read license text %s: %v\n", hdr.Name, err)
+			return
+		}
+		if hdr.Name == `metadata.json` {
+			if err := json.Unmarshal(b, &licenseMetadata); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to read license metadata: %v\n", err)
+			}
+			continue
+		}
+		licenseTexts[strings.TrimSuffix(hdr.Name, `.txt`)] = string(b)
+	}
+}
+
+// licenseText returns the original text of the license with the given id,
+// which may be a custom license of the loaded project. Standard headers are
+// available with a `.header` suffix on the id.
+func licenseText(id string) (string, bool) {
+	return currentClassifier().licenseText(id)
+}
+
+// licenseText is licenseText, with the custom licenses of the classifier
+// state c.
+func (c classifierState) licenseText(id string) (string, bool) {
+	if text, ok := c.texts[id]; ok {
+		return text, true
+	}
+	licenseTextsOnce.Do(loadLicenseTexts)
+	text, ok := licenseTexts[id]
+	return text, ok
+}
+
+// licenseInfo returns the metadata of the license with the given id.
+func licenseInfo(id string) (LicenseInfo, bool) {
+	licenseTextsOnce.Do(loadLicenseTexts)
+	info, ok := licenseMetadata.Licenses[id]
+	return info, ok
+}
+
+// licenseListVersion returns the version of the SPDX license list the
+// database was built from, if known.
+func licenseListVersion() string {
+	licenseTextsOnce.Do(loadLicenseTexts)
+	return licenseMetadata.ListVersion
+}
+
+// describe summarizes the metadata of a license for output, such as
+// "MIT License, OSI approved, FSF libre".
+func (info LicenseInfo) describe() string {
+	parts := []string{info.Name}
+	if info.Exception {
+		parts = append(parts, `license exception`)
+	}
+	if info.OSIApproved {
+		parts = append(parts, `OSI approved`)
+	}
+	if info.FSFLibre {
+		parts = append(parts, `FSF libre`)
+	}
+	if info.Deprecated {
+		parts = append(parts, `deprecated SPDX id`)
+	}
+	return strings.Join(parts, `, `)
+}
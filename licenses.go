@@ -0,0 +1,205 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+const licensesUsage = `Usage: weasel licenses list
+       weasel licenses show <id>
+       weasel licenses search <keyword>
+       weasel licenses version`
+
+// licensesCommand inspects the license database the classifier was built
+// from, including any custom licenses of the project in the working
+// directory.
+func licensesCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, licensesUsage)
+		return 1
+	}
+
+	if _, err := enterProject(`.`); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	if err := loadConfig(); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	if err := loadCustomLicenses(); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	ids, err := archiveLicenseIDs(licenseArchive)
+	if err != nil {
+		fmt.Printf("Failed to read license database: %v\n", err)
+		return 1
+	}
+
+	sorted := sortedLicenseIDs(ids)
+	switch {
+	case args[0] == `list` && len(args) == 1:
+		for _, id := range sorted {
+			fmt.Println(describeLicense(id, ids))
+		}
+	case args[0] == `show` && len(args) == 2:
+		return showLicense(args[1], ids)
+	case args[0] == `search` && len(args) == 2:
+		keyword := strings.ToLower(args[1])
+		for _, id := range sorted {
+			if licenseMatchesKeyword(id, keyword) {
+				fmt.Println(describeLicense(id, ids))
+			}
+		}
+	case args[0] == `version` && len(args) == 1:
+		version := licenseListVersion()
+		if version == `` {
+			version = `unknown`
+		}
+		fmt.Printf("SPDX license list: %s\n", version)
+		fmt.Printf("Licenses:          %d\n", len(ids))
+		fmt.Printf("Checksum:          sha256:%x\n", sha256.Sum256(licenseArchive))
+	default:
+		fmt.Fprintln(os.Stderr, licensesUsage)
+		return 1
+	}
+	return 0
+}
+
+// archiveLicenseIDs returns the ids of the licenses in a classifier archive,
+// mapped to whether a standard header is also in the archive.
+func archiveLicenseIDs(archive []byte) (map[string]bool, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	ids := make(map[string]bool)
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return ids, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(ioutil.Discard, tr); err != nil {
+			return nil, err
+		}
+		if !strings.HasSuffix(hdr.Name, `.txt`) {
+			continue
+		}
+		id := strings.TrimSuffix(hdr.Name, `.txt`)
+		if strings.HasSuffix(id, `.header`) {
+			ids[strings.TrimSuffix(id, `.header`)] = true
+		} else if _, ok := ids[id]; !ok {
+			ids[id] = false
+		}
+	}
+}
+
+// sortedLicenseIDs returns the keys of ids in order.
+func sortedLicenseIDs(ids map[string]bool) []string {
+	var sorted []string
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// describeLicense formats a line of `weasel licenses list`: the id, whether
+// a standard header is available, and the license's name.
+func describeLicense(id string, ids map[string]bool) string {
+	header := `-`
+	if ids[id] {
+		header = `header`
+	}
+	name := ``
+	if info, ok := licenseInfo(id); ok {
+		name = info.Name
+	}
+	return strings.TrimSpace(fmt.Sprintf("%-40s %-7s %s", id, header, name))
+}
+
+// licenseMatchesKeyword reports whether the lowercase keyword occurs in the
+// id, name or text of a license.
+func licenseMatchesKeyword(id string, keyword string) bool {
+	if strings.Contains(strings.ToLower(id), keyword) {
+		return true
+	}
+	if info, ok := licenseInfo(id); ok && strings.Contains(strings.ToLower(info.Name), keyword) {
+		return true
+	}
+	text, _ := licenseText(id)
+	return strings.Contains(strings.ToLower(text), keyword)
+}
+
+// showLicense prints the metadata and full text of a license.
+func showLicense(id string, ids map[string]bool) int {
+	header, ok := ids[id]
+	if !ok {
+		fmt.Printf("%s is not in the license database!\n", id)
+		return 1
+	}
+
+	fmt.Printf("%-12s %s\n", `Id`, id)
+	if info, ok := licenseInfo(id); ok {
+		fmt.Printf("%-12s %s\n", `Name`, info.describe())
+		for _, url := range info.SeeAlso {
+			fmt.Printf("%-12s %s\n", `See also`, url)
+		}
+	}
+	if header {
+		fmt.Printf("%-12s %s\n", `Header`, `available`)
+	} else {
+		fmt.Printf("%-12s %s\n", `Header`, `none`)
+	}
+
+	text, ok := licenseText(id)
+	if !ok {
+		fmt.Println(`No original text is available for this license.`)
+		return 0
+	}
+	fmt.Println()
+	fmt.Println(strings.TrimRight(text, "\n"))
+	if header {
+		if text, ok := licenseText(id + `.header`); ok {
+			fmt.Println()
+			fmt.Println(`Standard header:`)
+			fmt.Println()
+			fmt.Println(strings.TrimRight(text, "\n"))
+		}
+	}
+	return 0
+}
@@ -0,0 +1,459 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// The JSON-RPC error codes used by the language server.
+const (
+	lspParseError     = -32700
+	lspMethodNotFound = -32601
+	lspInvalidParams  = -32602
+)
+
+// lspMessage is a JSON-RPC request, response or notification.
+type lspMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type lspResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+}
+
+type lspErrorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   lspError         `json:"error"`
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type lspNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"` // In UTF-16 code units.
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Code     string   `json:"code,omitempty"`
+	Message  string   `json:"message"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspCodeAction struct {
+	Title       string          `json:"title"`
+	Kind        string          `json:"kind"`
+	Diagnostics []lspDiagnostic `json:"diagnostics,omitempty"`
+	Edit        struct {
+		Changes map[string][]lspTextEdit `json:"changes"`
+	} `json:"edit"`
+}
+
+type lspTextDocument struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+// lspServer checks the documents open in an editor, publishing the failures
+// weasel would report for them as diagnostics.
+type lspServer struct {
+	in        *bufio.Reader
+	out       io.Writer
+	root      string
+	documents map[string]string // Contents by URI, including unsaved changes.
+	shutdown  bool
+}
+
+// lspCommand runs a language server over stdin and stdout.
+func lspCommand(args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, "Usage: weasel lsp")
+		return 1
+	}
+	s := &lspServer{in: bufio.NewReader(os.Stdin), out: os.Stdout, documents: make(map[string]string)}
+	return s.serve()
+}
+
+// serve handles messages until the client exits, and returns the exit code
+// the protocol asks for: 0 if the client shut the server down first.
+func (s *lspServer) serve() int {
+	for {
+		msg, err := s.read()
+		if err == io.EOF {
+			return 1
+		}
+		if err != nil {
+			s.replyError(nil, lspParseError, err.Error())
+			continue
+		}
+		if msg.Method == `exit` {
+			if s.shutdown {
+				return 0
+			}
+			return 1
+		}
+		result, rpcErr := s.handle(msg)
+		if msg.ID == nil {
+			continue
+		}
+		if rpcErr != nil {
+			s.replyError(msg.ID, rpcErr.Code, rpcErr.Message)
+		} else {
+			s.write(lspResponse{JSONRPC: `2.0`, ID: msg.ID, Result: result})
+		}
+	}
+}
+
+// read reads a message, framed by a Content-Length header.
+func (s *lspServer) read() (*lspMessage, error) {
+	length := -1
+	for {
+		line, err := s.in.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == `` {
+			break
+		}
+		parts := strings.SplitN(line, `:`, 2)
+		if len(parts) == 2 && strings.EqualFold(strings.TrimSpace(parts[0]), `Content-Length`) {
+			if length, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+				return nil, fmt.Errorf("Bad Content-Length: %v", err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("Missing Content-Length")
+	}
+
+	b := make([]byte, length)
+	if _, err := io.ReadFull(s.in, b); err != nil {
+		return nil, err
+	}
+	var msg lspMessage
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return nil, fmt.Errorf("Malformed message: %v", err)
+	}
+	return &msg, nil
+}
+
+func (s *lspServer) write(msg interface{}) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(b), b)
+}
+
+func (s *lspServer) replyError(id *json.RawMessage, code int, message string) {
+	s.write(lspErrorResponse{JSONRPC: `2.0`, ID: id, Error: lspError{code, message}})
+}
+
+func (s *lspServer) notify(method string, params interface{}) {
+	s.write(lspNotification{JSONRPC: `2.0`, Method: method, Params: params})
+}
+
+// handle handles a request or notification, returning the result of a
+// request.
+func (s *lspServer) handle(msg *lspMessage) (interface{}, *lspError) {
+	var params struct {
+		RootURI      string          `json:"rootUri"`
+		RootPath     string          `json:"rootPath"`
+		TextDocument lspTextDocument `json:"textDocument"`
+		Text         *string         `json:"text"`
+		Changes      []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+	}
+	if len(msg.Params) != 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &lspError{lspInvalidParams, err.Error()}
+		}
+	}
+	uri := params.TextDocument.URI
+
+	switch msg.Method {
+	case `initialize`:
+		root := params.RootPath
+		if u, err := url.Parse(params.RootURI); err == nil && u.Scheme == `file` {
+			root = u.Path
+		}
+		if root != `` {
+			if err := os.Chdir(root); err != nil {
+				return nil, &lspError{lspInvalidParams, err.Error()}
+			}
+		}
+		if _, err := enterProject(`.`); err != nil {
+			return nil, &lspError{lspInvalidParams, err.Error()}
+		}
+		s.root, _ = os.Getwd()
+		if err := loadProject(); err != nil {
+			s.logError(err)
+		}
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{
+					"openClose": true,
+					"change":    1, // The whole document is sent on each change.
+					"save":      map[string]bool{"includeText": true},
+				},
+				"codeActionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "weasel", "version": Version},
+		}, nil
+	case `shutdown`:
+		s.shutdown = true
+		return nil, nil
+	case `textDocument/didOpen`:
+		s.documents[uri] = params.TextDocument.Text
+		s.check(uri)
+	case `textDocument/didChange`:
+		if len(params.Changes) != 0 {
+			s.documents[uri] = params.Changes[len(params.Changes)-1].Text
+		}
+		s.check(uri)
+	case `textDocument/didSave`:
+		if params.Text != nil {
+			s.documents[uri] = *params.Text
+		}
+		if name, ok := s.relative(uri); ok && projectFile(name) {
+			if err := reloadProject(); err != nil {
+				s.logError(err)
+			}
+			for open := range s.documents {
+				s.check(open)
+			}
+			return nil, nil
+		}
+		s.check(uri)
+	case `textDocument/didClose`:
+		delete(s.documents, uri)
+		s.notify(`textDocument/publishDiagnostics`, map[string]interface{}{"uri": uri, "diagnostics": []lspDiagnostic{}})
+	case `textDocument/codeAction`:
+		return s.codeActions(uri), nil
+	default:
+		if msg.ID != nil && !strings.HasPrefix(msg.Method, `$/`) {
+			return nil, &lspError{lspMethodNotFound, "Unsupported method " + msg.Method}
+		}
+	}
+	return nil, nil
+}
+
+// logError shows an error loading the project in the client's log.
+func (s *lspServer) logError(err error) {
+	s.notify(`window/logMessage`, map[string]interface{}{"type": 1, "message": err.Error()})
+}
+
+// relative returns the path of a document relative to the project root, if
+// it is a file inside the project.
+func (s *lspServer) relative(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != `file` || s.root == `` {
+		return ``, false
+	}
+	name, err := filepath.Rel(s.root, u.Path)
+	if err != nil || name == `..` || strings.HasPrefix(name, `../`) {
+		return ``, false
+	}
+	return filepath.ToSlash(name), true
+}
+
+// documentLicenses classifies an open document from its contents in the
+// editor, as scan classifies the file, and marks the licenses that fail the
+// check with a `!`. It returns nil for ignored documents.
+func (s *lspServer) documentLicenses(name string, content string) []License {
+	for _, part := range strings.Split(name, `/`) {
+		if part == `.git` {
+			return nil
+		}
+	}
+	if Ignored(name) {
+		return nil
+	}
+
+	lics := classifyWith(name, int64(len(content)), func(source string) ([]License, error) {
+		if source == name {
+			return contentLicenses(name, []byte(content))
+		}
+		return fileLicenses(source)
+	})
+	if len(lics) == 0 || isTooLarge(lics) {
+		_, inherited := inherit(name, func(licPath string) []License {
+			fi, err := os.Stat(licPath)
+			if err != nil || fi.IsDir() || Ignored(licPath) {
+				return nil
+			}
+			return classify(licPath, fi)
+		})
+		if len(inherited) != 0 {
+			lics = inherited
+		}
+	}
+	if len(lics) == 0 {
+		return []License{License(`Unknown!`)}
+	}
+	if isTooLarge(lics) {
+		return []License{tooLarge + `!`}
+	}
+	if _, ignore, _ := verdict(lics); ignore {
+		return nil
+	}
+	markUndocumented(name, lics)
+	return lics
+}
+
+// check publishes the diagnostics for an open document.
+func (s *lspServer) check(uri string) {
+	name, ok := s.relative(uri)
+	if !ok {
+		return
+	}
+	content := s.documents[uri]
+	s.notify(`textDocument/publishDiagnostics`, map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": s.diagnostics(name, content),
+	})
+}
+
+// diagnostics reports each failing license of a document, on the
+// SPDX-License-Identifier line that gave it, if any, or else on the first
+// line.
+func (s *lspServer) diagnostics(name string, content string) []lspDiagnostic {
+	diagnostics := []lspDiagnostic{}
+	lines := strings.Split(content, "\n")
+	firstLine := lspRange{End: lspPosition{0, utf16Len(strings.TrimRight(lines[0], "\r"))}}
+
+	tags := spdxTags([]byte(content))
+	for _, lic := range s.documentLicenses(name, content) {
+		if !strings.HasSuffix(string(lic), `!`) {
+			continue
+		}
+		id := strings.TrimRight(string(lic), `!~`)
+
+		d := lspDiagnostic{Range: firstLine, Severity: 1, Source: `weasel`, Code: id}
+		switch {
+		case lic == License(`Unknown!`):
+			d.Message = "No license found. Add a license header, or document the file in .dependency_license."
+		case strings.HasSuffix(strings.TrimSuffix(string(lic), `!`), `~`):
+			d.Message = fmt.Sprintf("%s, inherited from a LICENSE file, is not documented for this file by an @ line in LICENSE.", id)
+		default:
+			d.Message = fmt.Sprintf("%s is not documented for this file by an @ line in a LICENSE section for %s.", id, id)
+		}
+		for _, tag := range tags {
+			if string(tag.License) == id && tag.Line <= len(lines) {
+				line := strings.TrimRight(lines[tag.Line-1], "\r")
+				start := strings.Index(line, `SPDX-License-Identifier:`)
+				d.Range = lspRange{lspPosition{tag.Line - 1, utf16Len(line[:start])}, lspPosition{tag.Line - 1, utf16Len(line)}}
+				break
+			}
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+// codeActions offers to insert the project's license header into a document
+// in which no license was found.
+func (s *lspServer) codeActions(uri string) []lspCodeAction {
+	actions := []lspCodeAction{}
+	name, ok := s.relative(uri)
+	if !ok {
+		return actions
+	}
+	content := s.documents[uri]
+	var unknown []lspDiagnostic
+	for _, d := range s.diagnostics(name, content) {
+		if d.Code == `Unknown` {
+			unknown = append(unknown, d)
+		}
+	}
+	if len(unknown) == 0 || isBinary([]byte(content)) {
+		return actions
+	}
+	style, ok := styleFor(name, []byte(content))
+	if !ok {
+		return actions
+	}
+	header, err := headerText(projectFor(name))
+	if err != nil {
+		s.logError(err)
+		return actions
+	}
+
+	original := strings.SplitAfter(content, "\n")
+	_, at, inserted := insertHeader(name, style, content, header)
+	text := strings.Join(inserted, ``)
+	pos := lspPosition{Line: at}
+	if at > 0 && !strings.HasSuffix(original[at-1], "\n") {
+		// The header goes after a last line without a newline.
+		pos = lspPosition{at - 1, utf16Len(original[at-1])}
+		if strings.Contains(content, "\r\n") {
+			text = "\r\n" + text
+		} else {
+			text = "\n" + text
+		}
+	}
+
+	action := lspCodeAction{Title: "Insert license header", Kind: `quickfix`, Diagnostics: unknown}
+	action.Edit.Changes = map[string][]lspTextEdit{uri: {{lspRange{pos, pos}, text}}}
+	return append(actions, action)
+}
+
+// utf16Len returns the length of s in UTF-16 code units, in which LSP counts
+// characters.
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
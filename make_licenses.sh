@@ -16,38 +16,23 @@
 # 
 # SPDX-License-Identifier: Apache-2.0
 
-echo "Getting SPDX license data."
-[ -d spdx-tools ] && rm -rf spdx-tools
-mkdir spdx-tools
-cd spdx-tools
-git init
-git remote add origin https://github.com/spdx/tools.git
-git fetch --depth=1 origin master
-git config core.sparseCheckout true
-mkdir -p .git/info
-echo "resources/stdlicenses" >> .git/info/sparse-checkout
-git pull origin master
-cd ..
-[ -d spdx_licenses ] && rm -rf spdx_licenses
-mkdir spdx_licenses
-for l in $(ls spdx-tools/resources/stdlicenses/*.jsonld); do
-	id=$(jq -r .licenseId $l)
-	if [ -n $id -a $id != "null" ]; then
-		echo "Getting license data for $id."
-		txt="$(jq -r .licenseText $l)"
-		[ -n "$txt" -a "$txt" != "null" ] && jq -r .licenseText $l > spdx_licenses/$id.txt
-		hdr="$(jq -r .standardLicenseHeader $l)"
-		[ -n "$hdr" -a "$hdr" != "null" ] && jq -r .standardLicenseHeader $l > spdx_licenses/$id.header.txt
-	fi
-done
-
-echo "Building dbmaker."
-cd dbmaker
-go build
-cd ..
-
-echo "Compiling licenses into database."
-./dbmaker/dbmaker spdx_licenses spdx.db
+# Builds the license database from a local checkout of the SPDX license list
+# data, so that no network access is needed. Get one with:
+#
+#     git clone --depth=1 https://github.com/spdx/license-list-data
+#
+# and pass its path as the first argument, or set SPDX_LICENSE_LIST_DATA.
+# It defaults to license-list-data in the current directory.
+set -e
+
+data="${1:-${SPDX_LICENSE_LIST_DATA:-license-list-data}}"
+if [ ! -f "$data/json/licenses.json" ]; then
+	echo "No SPDX license list data at $data. Clone https://github.com/spdx/license-list-data there, or pass its path." >&2
+	exit 1
+fi
+
+echo "Compiling licenses from $data into database."
+go run ./dbmaker "$data" spdx.db spdx-data.db
 
 echo "Embedding database."
 cat >licensedb.go <<EOF
@@ -73,14 +58,20 @@ SPDX-License-Identifier: Apache-2.0
 
 package main
 
-var LicenseDBContents []byte = []byte{
 EOF
 
-
-od -A n -t x1 spdx.db | sed 's/^/0x/;s/ //;s/ /,0x/g;s/$/,/' >> licensedb.go
-
-cat >>licensedb.go <<EOF
+embed() {
+	echo "var $1 []byte = []byte{" >> licensedb.go
+	od -A n -v -t x1 $2 | sed 's/^/0x/;s/ //;s/ /,0x/g;s/$/,/' >> licensedb.go
+	echo "}" >> licensedb.go
+	echo >> licensedb.go
 }
-EOF
+
+# LicenseDBContents is the classifier's archive of normalized license texts.
+embed LicenseDBContents spdx.db
+# LicenseDataContents is an archive of the original license texts.
+embed LicenseDataContents spdx-data.db
 
 gofmt -w licensedb.go
+
+rm -f spdx.db spdx-data.db
@@ -0,0 +1,163 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/google/licenseclassifier"
+)
+
+var noticeFileNames = []string{`NOTICE`, `NOTICE.txt`, `NOTICE.md`}
+
+// isNotice reports whether name is a NOTICE file.
+func isNotice(name string) bool {
+	base := path.Base(name)
+	for _, n := range noticeFileNames {
+		if base == n {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyDirs are the names of the directories that dependencies are
+// vendored or bundled into.
+var dependencyDirs = map[string]bool{
+	`vendor`: true, `node_modules`: true, `third_party`: true,
+	`third-party`: true, `thirdparty`: true, `external`: true, `deps`: true,
+}
+
+// isDependencyNotice reports whether name is a NOTICE file inside one of the
+// dependencyDirs.
+func isDependencyNotice(name string) bool {
+	if !isNotice(name) {
+		return false
+	}
+	for _, dir := range strings.Split(path.Dir(name), `/`) {
+		if dependencyDirs[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingNotice is a NOTICE file from a dependency whose attribution is not
+// carried by the project's own NOTICE file.
+type MissingNotice struct {
+	Name   string
+	Holder string // The copyright holder named in the NOTICE, if any.
+}
+
+func (m MissingNotice) String() string {
+	if m.Holder == `` {
+		return m.Name
+	}
+	return m.Name + ` (` + m.Holder + `)`
+}
+
+// missingNotices checks every NOTICE file of a dependency among names, and
+// returns those whose attributions the top-level NOTICE file doesn't carry:
+// their whole content, or all of their copyright statements, or a reference
+// to the NOTICE file by its path.
+func missingNotices(names []string) []MissingNotice {
+	return missingNoticesWith(names, ioutil.ReadFile)
+}
+
+// missingNoticesWith is missingNotices, reading the files with readFile.
+func missingNoticesWith(names []string, readFile func(string) ([]byte, error)) []MissingNotice {
+	var top string
+	for _, n := range noticeFileNames {
+		if b, err := readFile(n); err == nil {
+			top = flattenSpace(string(b))
+			break
+		}
+	}
+
+	var missing []MissingNotice
+	for _, name := range names {
+		if !isDependencyNotice(name) {
+			continue
+		}
+
+		b, err := readFile(name)
+		if err != nil {
+			missing = append(missing, MissingNotice{Name: name})
+			continue
+		}
+		content := flattenSpace(string(b))
+		if content == `` {
+			continue
+		}
+
+		if top != `` && (strings.Contains(top, content) || carriesCopyrights(top, b) || refersTo(top, name)) {
+			continue
+		}
+		missing = append(missing, MissingNotice{name, licenseclassifier.CopyrightHolder(string(b))})
+	}
+
+	sort.Slice(missing, func(i, j int) bool { return missing[i].Name < missing[j].Name })
+	return missing
+}
+
+// refersTo reports whether the flattened top-level NOTICE top names the
+// NOTICE file name by its path, such as "See vendor/foo/NOTICE." or
+// "./vendor/foo/NOTICE", and not merely a longer path containing it.
+func refersTo(top string, name string) bool {
+	for i := 0; ; {
+		j := strings.Index(top[i:], name)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(name)
+		before := strings.TrimSuffix(top[:start], `./`)
+		after := strings.TrimLeft(top[end:], `.`)
+		if (before == `` || !isPathByte(before[len(before)-1])) && (after == `` || !isPathByte(after[0])) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+// isPathByte reports whether c may be part of a path in a NOTICE file.
+func isPathByte(c byte) bool {
+	return c == '/' || c == '.' || c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// carriesCopyrights reports whether the flattened top-level NOTICE top
+// contains every copyright statement in notice. A notice without copyright
+// statements is only carried by its whole content.
+func carriesCopyrights(top string, notice []byte) bool {
+	copyrights := parseCopyrights(notice)
+	for _, c := range copyrights {
+		if !strings.Contains(top, flattenSpace(c.Statement)) {
+			return false
+		}
+	}
+	return len(copyrights) != 0
+}
+
+// flattenSpace collapses all runs of whitespace in s to single spaces, so
+// that reflowed text still compares equal.
+func flattenSpace(s string) string {
+	return strings.Join(strings.Fields(s), ` `)
+}
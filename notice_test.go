@@ -0,0 +1,114 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestIsDependencyNotice(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{`NOTICE`, false},
+		{`docs/NOTICE`, false},
+		{`vendor/github.com/foo/bar/NOTICE`, true},
+		{`web/node_modules/left-pad/NOTICE.md`, true},
+		{`third_party/zlib/NOTICE.txt`, true},
+		{`vendor/github.com/foo/bar/README`, false},
+		{`vendored/foo/NOTICE`, false},
+	}
+
+	for _, test := range tests {
+		if got := isDependencyNotice(test.name); got != test.want {
+			t.Errorf("isDependencyNotice(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRefersTo(t *testing.T) {
+	tests := []struct {
+		top  string
+		want bool
+	}{
+		{`See vendor/foo/NOTICE for its attributions.`, true},
+		{`See vendor/foo/NOTICE.`, true},
+		{`See ./vendor/foo/NOTICE`, true},
+		{`(vendor/foo/NOTICE)`, true},
+		{`Includes vendor/foo/.`, false},
+		{`See vendor/foo/NOTICE.txt`, false},
+		{`See a/vendor/foo/NOTICE`, false},
+		{`See a/vendor/foo/NOTICE and vendor/foo/NOTICE`, true},
+	}
+
+	for _, test := range tests {
+		if got := refersTo(flattenSpace(test.top), `vendor/foo/NOTICE`); got != test.want {
+			t.Errorf("refersTo(%q) = %v, want %v", test.top, got, test.want)
+		}
+	}
+}
+
+func TestMissingNotices(t *testing.T) {
+	files := map[string]string{
+		`NOTICE`: "My Project\nCopyright 2026 Me\n\n" +
+			"This product includes software from Foo:\n  Foo Library\n  Copyright 2019\n  The Foo Authors\n\n" +
+			"Copyright 2020 The Bar Authors\n\n" +
+			"See vendor/ref/NOTICE.\n\n" +
+			"This product includes vendor/dir/.\n",
+		`vendor/foo/NOTICE`:    "Foo Library\nCopyright 2019 The Foo Authors\n",
+		`vendor/bar/NOTICE`:    "Bar\nCopyright 2020 The Bar Authors\n",
+		`vendor/baz/NOTICE`:    "Baz\nCopyright 2021 The Baz Authors\n",
+		`vendor/ref/NOTICE`:    "Ref\nCopyright 2022 The Ref Authors\n",
+		`vendor/dir/NOTICE`:    "Dir\nCopyright 2023 The Dir Authors\n",
+		`vendor/text/NOTICE`:   "Attributions are required.\n",
+		`vendor/empty/NOTICE`:  " \n",
+		`docs/NOTICE`:          "Copyright 2024 The Docs Authors\n",
+		`vendor/baz/README.md`: "Copyright 2021 The Baz Authors\n",
+	}
+	readFile := func(name string) ([]byte, error) {
+		if content, ok := files[name]; ok {
+			return []byte(content), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	names = append(names, `vendor/gone/NOTICE`)
+
+	want := []MissingNotice{
+		{`vendor/baz/NOTICE`, `The Baz Authors`},
+		{`vendor/dir/NOTICE`, `The Dir Authors`},
+		{`vendor/gone/NOTICE`, ``},
+		{`vendor/text/NOTICE`, ``},
+	}
+	if got := missingNoticesWith(names, readFile); !reflect.DeepEqual(got, want) {
+		t.Errorf("missingNoticesWith() = %+v, want %+v", got, want)
+	}
+
+	delete(files, `NOTICE`)
+	if got := missingNoticesWith([]string{`vendor/foo/NOTICE`}, readFile); len(got) != 1 {
+		t.Errorf("missingNoticesWith() without a top-level NOTICE = %+v, want vendor/foo/NOTICE", got)
+	}
+}
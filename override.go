@@ -20,6 +20,8 @@ package main
 
 import (
 	"bufio"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -28,22 +30,55 @@ import (
 
 var override = make(map[string][]License)
 
-func loadOverrides() {
-	filepath.Walk(".", func(name string, info os.FileInfo, err error) error {
-		if filepath.Base(name) == `.git` {
-			return filepath.SkipDir
-		}
-		if info.IsDir() {
-			return nil
-		}
-		if strings.HasSuffix(name, `.dependency_license`) {
-			loadOverrideFile(name, false)
-		}
-		if strings.Contains(name, `.dependency_licenses`+string(os.PathSeparator)) {
-			loadOverrideFile(name, true)
+// overrideRule records where a .dependency_license line came from, so the
+// reason for an override can be explained.
+type overrideRule struct {
+	Source  string
+	Line    int
+	Text    string
+	License License
+}
+
+var overrideRules = make(map[string][]overrideRule)
+
+// overrideFilter is a line of an override file: files matching Regexp get
+// License.
+type overrideFilter struct {
+	License License
+	Regexp  *regexp.Regexp
+	Rule    overrideRule
+}
+
+// overrideFilters are the lines of all the override files, in the order they
+// were loaded, for files found only in git history.
+var overrideFilters []overrideFilter
+
+// overridesFor returns the licenses that overrides give name, which needn't
+// exist in the working tree.
+func overridesFor(name string) []License {
+	return overridesIn(overrideFilters, name)
+}
+
+// overridesIn returns the licenses that filters give name.
+func overridesIn(filters []overrideFilter, name string) []License {
+	var lics []License
+	for _, filter := range filters {
+		if filter.Regexp.MatchString(name) {
+			lics = append(lics, filter.License)
 		}
-		return nil
-	})
+	}
+	return lics
+}
+
+// loadOverrides loads the override files found by walkProjectTree.
+func loadOverrides(tree projectTree) {
+	override = make(map[string][]License)
+	overrideRules = make(map[string][]overrideRule)
+	overrideFilters = nil
+
+	for _, f := range tree.overrideFiles {
+		loadOverrideFile(f.name, f.inDir)
+	}
 }
 
 func loadOverrideFile(overrideFile string, isDir bool) {
@@ -57,6 +92,35 @@ func loadOverrideFile(overrideFile string, isDir bool) {
 	}
 	defer f.Close()
 
+	regexps, err := parseOverrideFile(overrideFile, isDir, f)
+	if err != nil {
+		panic(err.Error())
+	}
+	overrideFilters = append(overrideFilters, regexps...)
+
+	err = filepath.Walk(`.`, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		for _, filter := range regexps {
+			if filter.Regexp.MatchString(path) {
+				override[path] = append(override[path], filter.License)
+				overrideRules[path] = append(overrideRules[path], filter.Rule)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		panic(`Failed when enumerating working directory: ` + err.Error())
+	}
+}
+
+// parseOverrideFile parses the lines of the override file at overrideFile,
+// which is in a .dependency_licenses directory if isDir is set.
+func parseOverrideFile(overrideFile string, isDir bool, r io.Reader) ([]overrideFilter, error) {
 	prefix := filepath.Dir(overrideFile)
 	if isDir {
 		prefix = filepath.Dir(prefix) // for files in .dependency_files directories, apply the regexes to the parent directory
@@ -67,15 +131,12 @@ func loadOverrideFile(overrideFile string, isDir bool) {
 		prefix = regexp.QuoteMeta(prefix + string(filepath.Separator))
 	}
 
-	type licenseFilter struct {
-		License License
-		Regexp  *regexp.Regexp
-	}
-
-	var regexps []licenseFilter
+	var regexps []overrideFilter
 
-	s := bufio.NewScanner(f)
+	s := bufio.NewScanner(r)
+	lineNum := 0
 	for s.Scan() {
+		lineNum++
 		line := s.Text()
 		line = strings.TrimSpace(line)
 		if line == `` || line[0] == '#' {
@@ -84,7 +145,7 @@ func loadOverrideFile(overrideFile string, isDir bool) {
 
 		parts := strings.Split(line, ",")
 		if len(parts) < 2 {
-			panic("Malformed line in " + overrideFile + ": " + line)
+			return nil, fmt.Errorf("Malformed line in %s: %s", overrideFile, line)
 		}
 
 		strRe, lic := strings.Join(parts[:len(parts)-1], `,`), parts[len(parts)-1]
@@ -101,27 +162,10 @@ func loadOverrideFile(overrideFile string, isDir bool) {
 		}
 		re, cmpErr := regexp.Compile(strRe)
 		if cmpErr != nil {
-			panic("Malformed regexp: " + strRe + "\n" + cmpErr.Error())
-		}
-
-		regexps = append(regexps, licenseFilter{License(lic), re})
-	}
-
-	err = filepath.Walk(`.`, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+			return nil, fmt.Errorf("Malformed regexp: %s\n%s", strRe, cmpErr.Error())
 		}
 
-		for _, filter := range regexps {
-			if filter.Regexp.MatchString(path) {
-				override[path] = append(override[path], filter.License)
-			}
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		panic(`Failed when enumerating working directory: ` + err.Error())
+		regexps = append(regexps, overrideFilter{License(lic), re, overrideRule{overrideFile, lineNum, line, License(lic)}})
 	}
+	return regexps, s.Err()
 }
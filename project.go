@@ -0,0 +1,241 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Project is a directory with its own LICENSE file and configuration. The
+// project in the working directory is the root project. Any directory in it
+// with its own configFile is a nested project: a boundary inside which files
+// are checked against its LICENSE file and configuration alone, and don't
+// inherit licenses from above. A directory with only a LICENSE file, such as
+// a vendored dependency, isn't one. A nested project's LICENSE
+// file itself is part of the project it is nested in, which must document
+// it like any other file under another license.
+type Project struct {
+	Dir        string // Relative to the root project, which is ".".
+	Config     Config
+	Documented Documented
+}
+
+// projects are the nested projects, deepest first, and then the root
+// project.
+var projects []*Project
+
+// projectTree is what loadProject finds in its walk of the project.
+type projectTree struct {
+	// overrideFiles are the .dependency_license files, and the files in
+	// .dependency_licenses directories, in the order they were found.
+	overrideFiles []overrideFile
+	projectDirs   []string // Directories with a configFile.
+}
+
+type overrideFile struct {
+	name  string
+	inDir bool // The file is in a .dependency_licenses directory.
+}
+
+// walkProjectTree walks the project in the working directory once, for the
+// files that loadProject reads.
+func walkProjectTree() projectTree {
+	var tree projectTree
+	filepath.Walk(`.`, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if filepath.Base(name) == `.git` {
+			return filepath.SkipDir
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if strings.HasSuffix(name, `.dependency_license`) {
+			tree.overrideFiles = append(tree.overrideFiles, overrideFile{name, false})
+		}
+		if strings.Contains(name, `.dependency_licenses`+string(os.PathSeparator)) {
+			tree.overrideFiles = append(tree.overrideFiles, overrideFile{name, true})
+		}
+		if dir := filepath.Dir(name); filepath.Base(name) == configFile && dir != `.` {
+			tree.projectDirs = append(tree.projectDirs, dir)
+		}
+		return nil
+	})
+	return tree
+}
+
+// loadProjects reads the LICENSE file of the root project, and those of the
+// projects nested in it, in dirs. A nested project's configuration defaults
+// to the root project's, except that its license is that of its LICENSE
+// file, if the text before its `@` lines is a single license, and its header
+// is an SPDX line if its license isn't Apache-2.0. The root project's
+// configuration must already be loaded.
+func loadProjects(dirs []string) error {
+	root := &Project{Dir: `.`, Config: config}
+	lines, err := readLicenseLines(`.`)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot open LICENSE file: %s!\n", err.Error())
+	}
+	root.Documented = parseDocumented(lines, `.`)
+	documented = root.Documented
+
+	ps, err := nestProjects(root, dirs, ioutil.ReadFile)
+	if err != nil {
+		return err
+	}
+	projects = ps
+	return nil
+}
+
+// nestProjects returns the projects in dirs, reading their LICENSE and
+// configuration files with readFile, deepest first, and then root.
+func nestProjects(root *Project, dirs []string, readFile func(string) ([]byte, error)) ([]*Project, error) {
+	var nested []*Project
+	for _, dir := range dirs {
+		if Ignored(dir) {
+			continue
+		}
+		lines, err := readLicenseLinesWith(dir, readFile)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("Cannot read %s: %v", filepath.Join(dir, `LICENSE`), err)
+		}
+		d := parseDocumented(lines, dir)
+
+		base := root.Config
+		base.Header = ``
+		if own := ownLicense(lines, d); own != `` {
+			base.License = licenseID(own)
+		}
+		c, err := readConfigWith(filepath.Join(dir, configFile), base, dir, readFile)
+		if err != nil {
+			return nil, err
+		}
+		// The root's Apache-2.0 header doesn't suit a project under another
+		// license.
+		if c.Header == `` && c.License == `Apache-2.0` {
+			c.Header = root.Config.Header
+		} else if c.Header == `` {
+			c.Header = `spdx`
+		}
+		nested = append(nested, &Project{Dir: dir, Config: c, Documented: d})
+	}
+	sort.Slice(nested, func(i, j int) bool {
+		di, dj := strings.Count(nested[i].Dir, `/`), strings.Count(nested[j].Dir, `/`)
+		if di != dj {
+			return di > dj
+		}
+		return nested[i].Dir < nested[j].Dir
+	})
+	return append(nested, root), nil
+}
+
+// projectFor returns the innermost project that the file name is in.
+func projectFor(name string) *Project {
+	return projectIn(projects, name)
+}
+
+// projectIn returns the innermost of ps that the file name is in.
+func projectIn(ps []*Project, name string) *Project {
+	dir := filepath.Dir(filepath.Clean(name))
+	if filepath.Base(name) == `LICENSE` {
+		dir = filepath.Dir(dir)
+	}
+	for _, p := range ps {
+		if p.Dir == `.` || dir == p.Dir || strings.HasPrefix(dir, p.Dir+`/`) {
+			return p
+		}
+	}
+	return &Project{Dir: `.`, Config: config, Documented: documented}
+}
+
+// isProjectDir reports whether dir is the directory of a nested project.
+func isProjectDir(dir string) bool {
+	return isProjectDirIn(projects, dir)
+}
+
+// isProjectDirIn reports whether dir is the directory of a nested project of
+// ps.
+func isProjectDirIn(ps []*Project, dir string) bool {
+	dir = filepath.Clean(dir)
+	for _, p := range ps {
+		if p.Dir != `.` && p.Dir == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedProjects returns the projects, the root project first, and then the
+// nested projects by directory.
+func sortedProjects() []*Project {
+	sorted := append([]*Project(nil), projects...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Dir == `.` || sorted[j].Dir == `.` {
+			return sorted[i].Dir == `.` && sorted[j].Dir != `.`
+		}
+		return sorted[i].Dir < sorted[j].Dir
+	})
+	return sorted
+}
+
+// projectPath returns name relative to dir, the directory of a project.
+func projectPath(dir string, name string) string {
+	if dir == `.` {
+		return name
+	}
+	if rel, err := filepath.Rel(dir, name); err == nil {
+		return rel
+	}
+	return name
+}
+
+// rel returns name relative to the project's directory, as the patterns of
+// its `@` lines are.
+func (p *Project) rel(name string) string {
+	return projectPath(p.Dir, name)
+}
+
+// licensePath returns the path of the project's LICENSE file.
+func (p *Project) licensePath() string {
+	return filepath.Join(p.Dir, `LICENSE`)
+}
+
+// accepts reports whether lic may appear in the project without being
+// documented in its LICENSE file, because it is the project's own license or
+// not a license at all.
+func (p *Project) accepts(lic License) bool {
+	return accepted(lic) || lic == License(p.Config.License)
+}
+
+// needsDocumentation reports whether any of licenses must be documented in
+// the project's LICENSE file.
+func (p *Project) needsDocumentation(licenses []License) bool {
+	for _, lic := range licenses {
+		if !p.accepts(lic) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"testing"
+)
+
+func TestProjectAccepts(t *testing.T) {
+	p := &Project{Dir: `sdk`, Config: Config{License: `MIT`}}
+	tests := []struct {
+		lic  License
+		want bool
+	}{
+		{`MIT`, true},
+		{`Docs`, true},
+		{`Empty`, true},
+		{`Ignore`, true},
+		// Inherited licenses and headers are only the project's license
+		// where they are documented.
+		{`MIT~`, false},
+		{`MIT.header`, false},
+		{`MIT!`, false},
+		{`Apache-2.0`, false},
+	}
+
+	for _, test := range tests {
+		if got := p.accepts(test.lic); got != test.want {
+			t.Errorf("accepts(%q) = %v, want %v", test.lic, got, test.want)
+		}
+	}
+}
+
+func TestInheritIn(t *testing.T) {
+	ps := []*Project{{Dir: `sdk`}, {Dir: `.`}}
+	licenses := map[string][]License{
+		`LICENSE`:              {`Apache-2.0`},
+		`vendor/foo/LICENSE`:   {`MIT`},
+		`sdk/LICENSE`:          {`MIT`},
+		`sdk/vendor/COPYING`:   {`GPL-2.0-only`, `Docs`},
+		`docs/LICENSE`:         {`Docs`},
+		`third/bar/LICENSE.md`: {`BSD-3-Clause`},
+	}
+	lookup := func(name string) []License { return licenses[name] }
+
+	tests := []struct {
+		name    string
+		licPath string
+		want    []License
+	}{
+		{`vendor/foo/a.go`, `vendor/foo/LICENSE`, []License{`MIT~`}},
+		{`vendor/foo/sub/a.go`, `vendor/foo/LICENSE`, []License{`MIT~`}},
+		{`third/bar/a.go`, `third/bar/LICENSE.md`, []License{`BSD-3-Clause~`}},
+		{`sdk/vendor/a.c`, `sdk/vendor/COPYING`, []License{`GPL-2.0-only~`}},
+		{`docs/a.md`, `docs/LICENSE`, nil},
+		// Neither the root project's nor a nested project's own LICENSE
+		// file is inherited.
+		{`a.go`, ``, nil},
+		{`sdk/a.go`, ``, nil},
+		{`sdk/lib/a.go`, ``, nil},
+	}
+
+	for _, test := range tests {
+		licPath, got := inheritIn(ps, test.name, lookup)
+		if licPath != test.licPath || len(got) != len(test.want) {
+			t.Errorf("inheritIn(%q) = %q, %v, want %q, %v", test.name, licPath, got, test.licPath, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("inheritIn(%q) = %q, %v, want %q, %v", test.name, licPath, got, test.licPath, test.want)
+				break
+			}
+		}
+	}
+}
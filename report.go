@@ -0,0 +1,268 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// reportFormats are the formats the results of a scan can be written in.
+var reportFormats = map[string]func(w io.Writer, r Report, quiet bool){
+	"text": writeTextReport,
+	"json": writeJSONReport,
+}
+
+// Report is the result of a scan of a project.
+type Report struct {
+	Directory string       `json:"directory"`
+	Files     []FileReport `json:"files"`
+	Problems  []Problem    `json:"problems"`
+	// Holders are the distinct copyright holders named in the files.
+	Holders []HolderCount `json:"holders"`
+	Failed  bool          `json:"failed"`
+	// Projects are the results of the root project and the projects nested
+	// in it, if there are any nested projects. The files and problems are
+	// then grouped by project.
+	Projects []ProjectReport `json:"projects,omitempty"`
+	// Summary counts the results of a scan of the whole project.
+	Summary *ScanSummary `json:"summary,omitempty"`
+}
+
+// ProjectReport is the result for one of the projects in a scan.
+type ProjectReport struct {
+	Directory string `json:"directory"`
+	License   string `json:"license"`
+	Files     int    `json:"files"`
+	Failed    bool   `json:"failed"`
+}
+
+// FileReport is the result for a single file.
+type FileReport struct {
+	Name string `json:"name"`
+	// Project is the directory of the project the file is in, if there are
+	// nested projects.
+	Project string `json:"project,omitempty"`
+	// License is the verdict on the file's licenses, as printed in the
+	// text report, such as "MIT!".
+	License    string      `json:"license"`
+	Licenses   []License   `json:"licenses"`
+	Ignored    bool        `json:"ignored,omitempty"`
+	Error      bool        `json:"error,omitempty"`
+	Copyrights []Copyright `json:"copyrights,omitempty"`
+	// CopyrightError is why the file's copyright statements don't satisfy
+	// the copyright policy, if they don't.
+	CopyrightError string `json:"copyrightError,omitempty"`
+}
+
+// Problem is a failure that isn't about the license of a file, such as an
+// `@` line that documents no files, or a file without the copyright statement
+// required by the copyright policy.
+type Problem struct {
+	Category string `json:"category"`
+	Subject  string `json:"subject"`
+	// Project is the directory of the project the problem is in, if there
+	// are nested projects.
+	Project string `json:"project,omitempty"`
+}
+
+// buildReport collects the results of a scan, and the problems found with
+// the LICENSE and NOTICE files, into a report.
+func buildReport(dir string, files map[string][]License, copyrights map[string][]Copyright) Report {
+	return buildReportFor(dir, files, copyrights, true)
+}
+
+// buildReportFor is buildReport for files that are the whole project, or
+// only some of its files. Problems with the project as a whole, such as `@`
+// lines that document no files, are only reported for the whole project.
+func buildReportFor(dir string, files map[string][]License, copyrights map[string][]Copyright, whole bool) Report {
+	r := Report{Directory: dir, Files: []FileReport{}, Problems: []Problem{}}
+	grouped := len(projects) > 1
+	order := make(map[string]int)
+	for i, p := range sortedProjects() {
+		order[p.Dir] = i
+	}
+
+	var filenames []string
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	sort.Slice(filenames, func(i, j int) bool {
+		pi, pj := order[projectFor(filenames[i]).Dir], order[projectFor(filenames[j]).Dir]
+		if grouped && pi != pj {
+			return pi < pj
+		}
+		return filenames[i] < filenames[j]
+	})
+
+	for _, filename := range filenames {
+		licStr, ignore, undoc := verdict(files[filename])
+		p := projectFor(filename)
+		f := FileReport{
+			Name:       filename,
+			License:    licStr,
+			Licenses:   files[filename],
+			Ignored:    ignore,
+			Error:      undoc && !ignore,
+			Copyrights: copyrights[filename],
+		}
+		if grouped {
+			f.Project = p.Dir
+		}
+		if p.Config.Copyright != nil && !ignore {
+			f.CopyrightError = p.Config.Copyright.Check(filename, files[filename], copyrights[filename])
+		}
+		r.Files = append(r.Files, f)
+		if f.Error {
+			r.Failed = true
+		}
+	}
+	for _, f := range r.Files {
+		if f.CopyrightError != `` {
+			r.Problems = append(r.Problems, Problem{Category: "Copyright!", Subject: f.Name + ": " + f.CopyrightError, Project: f.Project})
+		}
+	}
+	for _, f := range r.Files {
+		if conflict := debianConflict(debianRules, f.Name, f.Licenses); conflict != `` && !f.Ignored {
+			r.Problems = append(r.Problems, Problem{Category: "Debian-Copyright!", Subject: f.Name + ": " + conflict, Project: f.Project})
+		}
+	}
+	if whole {
+		for _, p := range sortedProjects() {
+			extras := p.Documented.Extra(p.Dir)
+			sort.Strings(extras)
+			for _, extra := range extras {
+				problem := Problem{Category: "Extra-License!", Subject: extra}
+				if grouped {
+					problem.Subject = p.licensePath() + ": @" + extra
+					problem.Project = p.Dir
+				}
+				r.Problems = append(r.Problems, problem)
+			}
+		}
+	}
+	for _, missing := range missingNotices(filenames) {
+		r.Problems = append(r.Problems, Problem{Category: "Missing-Notice!", Subject: missing.String()})
+	}
+	if whole && isReuseProject() {
+		r.Problems = append(r.Problems, reuseLicenseTextProblems(files)...)
+	}
+	if len(r.Problems) != 0 {
+		r.Failed = true
+	}
+
+	if grouped {
+		// Problems that aren't about a nested project are the root's.
+		for i := range r.Problems {
+			if r.Problems[i].Project == `` {
+				r.Problems[i].Project = `.`
+			}
+		}
+		sort.SliceStable(r.Problems, func(i, j int) bool {
+			return order[r.Problems[i].Project] < order[r.Problems[j].Project]
+		})
+		for _, p := range sortedProjects() {
+			pr := ProjectReport{Directory: p.Dir, License: p.Config.License}
+			for _, f := range r.Files {
+				if f.Project == p.Dir {
+					pr.Files++
+					pr.Failed = pr.Failed || f.Error
+				}
+			}
+			for _, problem := range r.Problems {
+				pr.Failed = pr.Failed || problem.Project == p.Dir
+			}
+			r.Projects = append(r.Projects, pr)
+		}
+	}
+
+	r.Holders = copyrightHolders(copyrights)
+	if r.Holders == nil {
+		r.Holders = []HolderCount{}
+	}
+	return r
+}
+
+// writeTextReport writes the problematic files, or all files if quiet is
+// false, one per line with the verdict on their licenses, followed by their
+// copyright statements. If there are nested projects, the files and
+// problems of each project follow a line naming it and its license. The
+// summary, if the report has one, comes last.
+func writeTextReport(w io.Writer, r Report, quiet bool) {
+	if !quiet {
+		fmt.Fprintln(w, "In directory: "+r.Directory)
+	}
+	if r.Summary != nil {
+		defer writeSummaryText(w, r.Summary)
+	}
+	if len(r.Projects) == 0 {
+		writeTextResults(w, r.Files, r.Problems, quiet)
+		return
+	}
+	for _, p := range r.Projects {
+		if quiet && !p.Failed {
+			continue
+		}
+		var files []FileReport
+		for _, f := range r.Files {
+			if f.Project == p.Directory {
+				files = append(files, f)
+			}
+		}
+		var problems []Problem
+		for _, problem := range r.Problems {
+			if problem.Project == p.Directory {
+				problems = append(problems, problem)
+			}
+		}
+		fmt.Fprintf(w, "In project: %s (%s)\n", p.Directory, p.License)
+		writeTextResults(w, files, problems, quiet)
+	}
+}
+
+// writeTextResults writes files and problems for writeTextReport.
+func writeTextResults(w io.Writer, files []FileReport, problems []Problem, quiet bool) {
+	for _, f := range files {
+		if f.Ignored || (quiet && !f.Error) {
+			continue
+		}
+		errStr := ""
+		if f.Error {
+			errStr = "Error"
+		}
+		fmt.Fprintf(w, "%-6s%40s %s\n", errStr, f.License, f.Name)
+		for _, c := range f.Copyrights {
+			fmt.Fprintf(w, "%47s%s\n", "", c.Statement)
+		}
+	}
+	for _, p := range problems {
+		fmt.Fprintf(w, "%-6s%40s %s\n", "Error", p.Category, p.Subject)
+	}
+}
+
+// writeJSONReport writes the whole report, including every file and the
+// copyright holders, as JSON.
+func writeJSONReport(w io.Writer, r Report, quiet bool) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(``, `  `)
+	enc.Encode(r)
+}
@@ -0,0 +1,407 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The files and directories of the REUSE specification, https://reuse.software.
+const (
+	reuseVersion    = `3.3`
+	reuseLicenseDir = `LICENSES`
+	reuseTOMLFile   = `REUSE.toml`
+	reuseDep5File   = `.reuse/dep5`
+	reuseSidecarExt = `.license`
+)
+
+// The precedences of a REUSE.toml annotation over the tags in the files it
+// covers.
+const (
+	precedenceClosest   = `closest`   // The file's own tags, if it has any.
+	precedenceAggregate = `aggregate` // Both the file's tags and the annotation.
+	precedenceOverride  = `override`  // Only the annotation; the file isn't read.
+)
+
+// reuseAnnotation is an annotation in REUSE.toml, or a `Files:` paragraph of
+// .reuse/dep5, giving the licenses and copyrights of the files it matches.
+type reuseAnnotation struct {
+	Source     string // Where the annotation is, such as "REUSE.toml annotation 2".
+	Paths      []string
+	Precedence string
+	Licenses   []License
+	Copyrights []Copyright
+
+	match func(name string) bool
+}
+
+var reuseAnnotations []*reuseAnnotation
+
+// loadReuse reads the annotations of REUSE.toml or .reuse/dep5, if the
+// project has either.
+func loadReuse() error {
+	reuseAnnotations = nil
+
+	toml, tomlErr := ioutil.ReadFile(reuseTOMLFile)
+	dep5, dep5Err := os.Open(reuseDep5File)
+	if tomlErr == nil && dep5Err == nil {
+		dep5.Close()
+		return fmt.Errorf("%s and %s cannot both be used", reuseTOMLFile, reuseDep5File)
+	}
+
+	if tomlErr == nil {
+		annotations, err := parseReuseTOML(string(toml))
+		if err != nil {
+			return fmt.Errorf("Malformed %s: %v", reuseTOMLFile, err)
+		}
+		reuseAnnotations = annotations
+	} else if !os.IsNotExist(tomlErr) {
+		return fmt.Errorf("Cannot read %s: %v", reuseTOMLFile, tomlErr)
+	}
+
+	if dep5Err == nil {
+		defer dep5.Close()
+		annotations, err := parseReuseDep5(dep5, reuseDep5File)
+		if err != nil {
+			return fmt.Errorf("Malformed %s: %v", reuseDep5File, err)
+		}
+		reuseAnnotations = annotations
+	} else if !os.IsNotExist(dep5Err) {
+		return fmt.Errorf("Cannot read %s: %v", reuseDep5File, dep5Err)
+	}
+	return nil
+}
+
+// parseReuseTOML reads the annotations of a REUSE.toml file.
+func parseReuseTOML(text string) ([]*reuseAnnotation, error) {
+	doc, err := parseTOML(text)
+	if err != nil {
+		return nil, err
+	}
+	if version, ok := doc[`version`].(int64); !ok || version != 1 {
+		return nil, fmt.Errorf("unsupported version %v", doc[`version`])
+	}
+
+	tables, ok := doc[`annotations`].([]interface{})
+	if !ok && doc[`annotations`] != nil {
+		return nil, fmt.Errorf("annotations must be an array of tables")
+	}
+	var annotations []*reuseAnnotation
+	for i, t := range tables {
+		table, ok := t.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("annotation %d must be a table", i+1)
+		}
+		a := &reuseAnnotation{
+			Source:     fmt.Sprintf("%s annotation %d", reuseTOMLFile, i+1),
+			Precedence: precedenceClosest,
+		}
+
+		a.Paths, err = tomlStrings(table, `path`)
+		if err != nil || len(a.Paths) == 0 {
+			return nil, fmt.Errorf("annotation %d: path must be a string or an array of strings", i+1)
+		}
+		paths := a.Paths
+		a.match = func(name string) bool {
+			for _, pattern := range paths {
+				if ok, err := globMatch(pattern, name); ok && err == nil {
+					return true
+				}
+			}
+			return false
+		}
+
+		if p, ok := table[`precedence`]; ok {
+			a.Precedence, _ = p.(string)
+			if a.Precedence != precedenceClosest && a.Precedence != precedenceAggregate && a.Precedence != precedenceOverride {
+				return nil, fmt.Errorf("annotation %d: unknown precedence %v", i+1, p)
+			}
+		}
+
+		licenses, err := tomlStrings(table, `SPDX-License-Identifier`)
+		if err != nil {
+			return nil, fmt.Errorf("annotation %d: %v", i+1, err)
+		}
+		for _, lic := range licenses {
+			a.Licenses = append(a.Licenses, License(lic))
+		}
+		copyrights, err := tomlStrings(table, `SPDX-FileCopyrightText`)
+		if err != nil {
+			return nil, fmt.Errorf("annotation %d: %v", i+1, err)
+		}
+		for _, c := range copyrights {
+			a.Copyrights = append(a.Copyrights, taggedCopyright(c))
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, nil
+}
+
+// tomlStrings returns the value of key in table, which may be a string or an
+// array of strings.
+func tomlStrings(table map[string]interface{}, key string) ([]string, error) {
+	switch v := table[key].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		var strs []string
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a string or an array of strings", key)
+			}
+			strs = append(strs, s)
+		}
+		return strs, nil
+	}
+	return nil, fmt.Errorf("%s must be a string or an array of strings", key)
+}
+
+// parseReuseDep5 reads the `Files:` paragraphs of a DEP-5 file as
+// annotations, which are aggregated with the tags in the files they match.
+func parseReuseDep5(r io.Reader, source string) ([]*reuseAnnotation, error) {
+	paragraphs, err := parseDep5(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var annotations []*reuseAnnotation
+	for _, p := range paragraphs {
+		files := p.Field(`Files`)
+		if files == `` {
+			continue
+		}
+		a := &reuseAnnotation{
+			Source:     fmt.Sprintf("%s:%d", source, p.Line),
+			Paths:      strings.Fields(files),
+			Precedence: precedenceAggregate,
+			Copyrights: dep5Copyrights(p.Field(`Copyright`)),
+		}
+		if lic := strings.SplitN(p.Field(`License`), "\n", 2)[0]; lic != `` {
+			a.Licenses = []License{License(lic)}
+		}
+
+		var res []*regexp.Regexp
+		for _, pattern := range a.Paths {
+			re, err := dep5Pattern(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: bad pattern %s: %v", p.Line, pattern, err)
+			}
+			res = append(res, re)
+		}
+		a.match = func(name string) bool {
+			for _, re := range res {
+				if re.MatchString(name) {
+					return true
+				}
+			}
+			return false
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, nil
+}
+
+// reuseAnnotationFor returns the annotation covering name, or nil. When more
+// than one matches, the last one wins.
+func reuseAnnotationFor(name string) *reuseAnnotation {
+	var found *reuseAnnotation
+	for _, a := range reuseAnnotations {
+		if a.match(name) {
+			found = a
+		}
+	}
+	return found
+}
+
+// overrides reports whether the annotation replaces the tags of the files it
+// covers, so they needn't be read.
+func (a *reuseAnnotation) overrides() bool {
+	return a != nil && a.Precedence == precedenceOverride
+}
+
+// licenses combines the licenses found in a file with the annotation's,
+// according to its precedence.
+func (a *reuseAnnotation) licenses(own []License) []License {
+	if a == nil || (a.Precedence == precedenceClosest && len(own) != 0) {
+		return own
+	}
+	if a.Precedence == precedenceOverride {
+		return a.Licenses
+	}
+	return append(own, a.Licenses...)
+}
+
+// copyrights combines the copyrights found in a file with the annotation's,
+// according to its precedence.
+func (a *reuseAnnotation) copyrights(own []Copyright) []Copyright {
+	if a == nil || (a.Precedence == precedenceClosest && len(own) != 0) {
+		return own
+	}
+	if a.Precedence == precedenceOverride {
+		return a.Copyrights
+	}
+	for _, c := range a.Copyrights {
+		if !containsCopyright(own, c) {
+			own = append(own, c)
+		}
+	}
+	return own
+}
+
+// reuseSource returns the file to read for the licenses and copyrights of
+// name: its `.license` sidecar, if it has one, or else name itself.
+func reuseSource(name string) string {
+	if fi, err := os.Stat(name + reuseSidecarExt); err == nil && !fi.IsDir() {
+		return name + reuseSidecarExt
+	}
+	return name
+}
+
+// spdxExpressionIDs returns the license and exception ids in an SPDX license
+// expression, such as "GPL-2.0-or-later WITH Classpath-exception-2.0".
+func spdxExpressionIDs(expr string) []string {
+	var ids []string
+	for _, word := range strings.Fields(strings.NewReplacer(`(`, ` `, `)`, ` `).Replace(expr)) {
+		switch strings.ToUpper(word) {
+		case `AND`, `OR`, `WITH`:
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(word, `+`))
+	}
+	return ids
+}
+
+// reuseLicenseTexts returns the paths of the license texts in LICENSES by
+// their ids, the file names without their extensions.
+func reuseLicenseTexts() (map[string][]string, error) {
+	entries, err := ioutil.ReadDir(reuseLicenseDir)
+	if err != nil {
+		return nil, err
+	}
+	texts := make(map[string][]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		texts[id] = append(texts[id], filepath.Join(reuseLicenseDir, entry.Name()))
+	}
+	return texts, nil
+}
+
+// isReuseProject reports whether the project in the working directory
+// follows the REUSE specification: it has REUSE.toml or .reuse/dep5, or its
+// configuration says so.
+func isReuseProject() bool {
+	if config.Reuse {
+		return true
+	}
+	for _, name := range []string{reuseTOMLFile, reuseDep5File} {
+		if _, err := os.Stat(name); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// isReuseMetadata reports whether name holds the licenses of other files,
+// rather than being checked itself: it is the `.license` sidecar of an
+// existing file, or a license text in the LICENSES directory of a REUSE
+// project.
+func isReuseMetadata(name string) bool {
+	if strings.HasSuffix(name, reuseSidecarExt) {
+		if fi, err := os.Stat(strings.TrimSuffix(name, reuseSidecarExt)); err == nil && !fi.IsDir() {
+			return true
+		}
+	}
+	return strings.HasPrefix(name, reuseLicenseDir+`/`) && isReuseProject()
+}
+
+// reuseLicenseTextProblems checks that every license of the files in a scan
+// has a text in LICENSES, and that every text there is used.
+func reuseLicenseTextProblems(files map[string][]License) []Problem {
+	texts, err := reuseLicenseTexts()
+	if err != nil {
+		return []Problem{{Category: "Missing-License-Text!", Subject: err.Error()}}
+	}
+
+	used := make(map[string][]string)
+	for name, lics := range files {
+		if strings.HasPrefix(name, reuseLicenseDir+`/`) {
+			continue
+		}
+		for _, lic := range lics {
+			for _, id := range spdxExpressionIDs(licenseID(lic)) {
+				if isSPDXLicense(id) {
+					used[id] = append(used[id], name)
+				}
+			}
+		}
+	}
+
+	var problems []Problem
+	for _, id := range sortedKeys(used) {
+		if _, ok := texts[id]; !ok {
+			sort.Strings(used[id])
+			problems = append(problems, Problem{Category: "Missing-License-Text!", Subject: fmt.Sprintf("%s/%s.txt, used by %s", reuseLicenseDir, id, used[id][0])})
+		}
+	}
+	var ids []string
+	for id := range texts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if _, ok := used[id]; !ok {
+			for _, path := range texts[id] {
+				problems = append(problems, Problem{Category: "Unused-License-Text!", Subject: path})
+			}
+		}
+	}
+	return problems
+}
+
+// isSPDXLicense reports whether id is in the license database, or is a
+// license reference as allowed by SPDX.
+func isSPDXLicense(id string) bool {
+	if strings.HasPrefix(id, `LicenseRef-`) {
+		return true
+	}
+	_, ok := licenseText(id)
+	return ok
+}
+
+func sortedKeys(m map[string][]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseReuseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		// want are the paths, precedence and licenses of each annotation.
+		want []reuseAnnotation
+		err  string
+	}{
+		{
+			name: "no annotations",
+			text: "version = 1\n",
+		},
+		{
+			name: "string path",
+			text: "version = 1\n[[annotations]]\npath = \"docs/**\"\nSPDX-License-Identifier = \"CC-BY-4.0\"\n",
+			want: []reuseAnnotation{{Paths: []string{`docs/**`}, Precedence: precedenceClosest, Licenses: []License{`CC-BY-4.0`}}},
+		},
+		{
+			name: "array path and precedence",
+			text: "version = 1\n[[annotations]]\npath = [\"a.png\", \"b.png\"]\nprecedence = \"override\"\nSPDX-License-Identifier = [\"MIT\", \"Apache-2.0\"]\n",
+			want: []reuseAnnotation{{Paths: []string{`a.png`, `b.png`}, Precedence: precedenceOverride, Licenses: []License{`MIT`, `Apache-2.0`}}},
+		},
+		{
+			name: "missing version",
+			text: "[[annotations]]\npath = \"a\"\n",
+			err:  "unsupported version",
+		},
+		{
+			name: "wrong version",
+			text: "version = 2\n",
+			err:  "unsupported version",
+		},
+		{
+			name: "annotations not tables",
+			text: "version = 1\nannotations = [1]\n",
+			err:  "annotation 1 must be a table",
+		},
+		{
+			name: "annotations not an array",
+			text: "version = 1\nannotations = \"a\"\n",
+			err:  "annotations must be an array of tables",
+		},
+		{
+			name: "missing path",
+			text: "version = 1\n[[annotations]]\nSPDX-License-Identifier = \"MIT\"\n",
+			err:  "annotation 1: path",
+		},
+		{
+			name: "path not a string",
+			text: "version = 1\n[[annotations]]\npath = [1]\n",
+			err:  "annotation 1: path",
+		},
+		{
+			name: "unknown precedence",
+			text: "version = 1\n[[annotations]]\npath = \"a\"\nprecedence = \"nearest\"\n",
+			err:  "unknown precedence",
+		},
+		{
+			name: "license not a string",
+			text: "version = 1\n[[annotations]]\npath = \"a\"\nSPDX-License-Identifier = 1\n",
+			err:  "SPDX-License-Identifier must be",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			annotations, err := parseReuseTOML(test.text)
+			if test.err != `` {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Fatalf("got error %v, want one containing %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []reuseAnnotation
+			for _, a := range annotations {
+				got = append(got, reuseAnnotation{Paths: a.Paths, Precedence: a.Precedence, Licenses: a.Licenses})
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// reuseExempt matches the names of files that the REUSE specification doesn't
+// require licensing information for, because they are license texts.
+var reuseExempt = regexp.MustCompile(`^(LICEN[CS]E|COPYING)([.-].*)?$`)
+
+// reuseLintResult is the verdict of the REUSE specification on a project, as
+// `reuse lint` reports it.
+type reuseLintResult struct {
+	BadLicenses        map[string][]string // Ids that are neither SPDX nor LicenseRef-, by the files using them.
+	DeprecatedLicenses []string
+	NoExtension        []string            // License texts without a file extension.
+	MissingLicenses    map[string][]string // Ids without a text in LICENSES, by the files using them.
+	UnusedLicenses     []string            // License texts no file uses.
+	UsedLicenses       []string
+	ReadErrors         []string
+	Files              int
+	NoCopyright        []string
+	NoLicense          []string
+}
+
+// Compliant reports whether the project follows the REUSE specification.
+func (r reuseLintResult) Compliant() bool {
+	return len(r.BadLicenses) == 0 && len(r.DeprecatedLicenses) == 0 && len(r.NoExtension) == 0 &&
+		len(r.MissingLicenses) == 0 && len(r.UnusedLicenses) == 0 && len(r.ReadErrors) == 0 &&
+		len(r.NoCopyright) == 0 && len(r.NoLicense) == 0
+}
+
+// reuseCommand checks the project against the REUSE specification, like
+// `reuse lint`, and fails if it doesn't comply.
+func reuseCommand(args []string) int {
+	if len(args) > 1 || (len(args) == 1 && args[0] != `lint`) {
+		fmt.Fprintln(os.Stderr, "Usage: weasel reuse [lint]")
+		return 1
+	}
+	if _, err := enterProject(`.`); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	if err := loadProject(); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+
+	r, err := reuseLint()
+	if err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	writeReuseLint(os.Stdout, r)
+	if !r.Compliant() {
+		return 1
+	}
+	return 0
+}
+
+// reuseLint checks every file in the project for licensing and copyright
+// information, in its tags, its `.license` sidecar or REUSE.toml, and checks
+// the texts in LICENSES against the licenses used.
+func reuseLint() (reuseLintResult, error) {
+	r := reuseLintResult{
+		BadLicenses:     make(map[string][]string),
+		MissingLicenses: make(map[string][]string),
+	}
+	used := make(map[string][]string)
+
+	err := filepath.Walk(`.`, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			r.ReadErrors = append(r.ReadErrors, name)
+			return nil
+		}
+		if filepath.Base(name) == `.git` || name == reuseLicenseDir || name == filepath.Dir(reuseDep5File) {
+			return filepath.SkipDir
+		}
+		if info.IsDir() || (info.Mode()&os.ModeSymlink) != 0 || info.Size() == 0 || Ignored(name) {
+			return nil
+		}
+		if name == reuseTOMLFile || reuseExempt.MatchString(filepath.Base(name)) {
+			return nil
+		}
+		if strings.HasSuffix(name, reuseSidecarExt) {
+			if _, err := os.Stat(strings.TrimSuffix(name, reuseSidecarExt)); err == nil {
+				return nil
+			}
+		}
+
+		r.Files++
+		a := reuseAnnotationFor(name)
+		var lics []License
+		var copyrights []Copyright
+		if !a.overrides() {
+			source := reuseSource(name)
+			if lics, err = spdxLicenses(source); err != nil {
+				r.ReadErrors = append(r.ReadErrors, name)
+				return nil
+			}
+			if copyrights, err = fileCopyrights(source); err != nil {
+				r.ReadErrors = append(r.ReadErrors, name)
+				return nil
+			}
+		}
+		lics, copyrights = a.licenses(lics), a.copyrights(copyrights)
+
+		if len(lics) == 0 {
+			r.NoLicense = append(r.NoLicense, name)
+		}
+		if len(copyrights) == 0 {
+			r.NoCopyright = append(r.NoCopyright, name)
+		}
+		for _, lic := range lics {
+			for _, id := range spdxExpressionIDs(string(lic)) {
+				used[id] = append(used[id], name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return r, err
+	}
+
+	texts, err := reuseLicenseTexts()
+	if err != nil && !os.IsNotExist(err) {
+		return r, err
+	}
+	for id, paths := range texts {
+		for _, path := range paths {
+			if filepath.Ext(path) == `` {
+				r.NoExtension = append(r.NoExtension, path)
+			}
+			if !isSPDXLicense(id) {
+				r.BadLicenses[id] = append(r.BadLicenses[id], path)
+			}
+		}
+		if _, ok := used[id]; !ok {
+			r.UnusedLicenses = append(r.UnusedLicenses, paths...)
+		}
+	}
+	for id, names := range used {
+		if !isSPDXLicense(id) {
+			r.BadLicenses[id] = append(r.BadLicenses[id], names...)
+		} else if info, ok := licenseInfo(id); ok && info.Deprecated {
+			r.DeprecatedLicenses = append(r.DeprecatedLicenses, id)
+		}
+		if _, ok := texts[id]; !ok {
+			r.MissingLicenses[id] = names
+		}
+		r.UsedLicenses = append(r.UsedLicenses, id)
+	}
+
+	for _, list := range [][]string{r.DeprecatedLicenses, r.NoExtension, r.UnusedLicenses, r.UsedLicenses, r.ReadErrors, r.NoCopyright, r.NoLicense} {
+		sort.Strings(list)
+	}
+	return r, nil
+}
+
+// writeReuseLint writes the verdict in the form `reuse lint` does.
+func writeReuseLint(w io.Writer, r reuseLintResult) {
+	writeIDs := func(title string, ids map[string][]string) {
+		if len(ids) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "# %s\n\n", title)
+		for _, id := range sortedKeys(ids) {
+			fmt.Fprintf(w, "'%s' found in:\n", id)
+			names := ids[id]
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Fprintf(w, "* %s\n", name)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	writeList := func(title string, intro string, names []string) {
+		if len(names) == 0 {
+			return
+		}
+		if title != `` {
+			fmt.Fprintf(w, "# %s\n\n", title)
+		}
+		fmt.Fprintln(w, intro)
+		for _, name := range names {
+			fmt.Fprintf(w, "* %s\n", name)
+		}
+		fmt.Fprintln(w)
+	}
+
+	writeIDs(`BAD LICENSES`, r.BadLicenses)
+	writeList(`DEPRECATED LICENSES`, `The following licenses are deprecated by SPDX:`, r.DeprecatedLicenses)
+	writeList(`LICENSES WITHOUT FILE EXTENSION`, `The following licenses have no file extension:`, r.NoExtension)
+	writeIDs(`MISSING LICENSES`, r.MissingLicenses)
+	writeList(`UNUSED LICENSES`, `The following licenses are not used:`, r.UnusedLicenses)
+	writeList(`READ ERRORS`, `Could not read:`, r.ReadErrors)
+	if len(r.NoCopyright) != 0 || len(r.NoLicense) != 0 {
+		fmt.Fprintf(w, "# MISSING COPYRIGHT AND LICENSING INFORMATION\n\n")
+		writeList(``, `The following files have no copyright information:`, r.NoCopyright)
+		writeList(``, `The following files have no licensing information:`, r.NoLicense)
+	}
+
+	fmt.Fprintf(w, "# SUMMARY\n\n")
+	fmt.Fprintf(w, "* Bad licenses: %s\n", strings.Join(sortedKeys(r.BadLicenses), `, `))
+	fmt.Fprintf(w, "* Deprecated licenses: %s\n", strings.Join(r.DeprecatedLicenses, `, `))
+	fmt.Fprintf(w, "* Licenses without file extension: %s\n", strings.Join(r.NoExtension, `, `))
+	fmt.Fprintf(w, "* Missing licenses: %s\n", strings.Join(sortedKeys(r.MissingLicenses), `, `))
+	fmt.Fprintf(w, "* Unused licenses: %s\n", strings.Join(r.UnusedLicenses, `, `))
+	fmt.Fprintf(w, "* Used licenses: %s\n", strings.Join(r.UsedLicenses, `, `))
+	fmt.Fprintf(w, "* Read errors: %d\n", len(r.ReadErrors))
+	fmt.Fprintf(w, "* Files with copyright information: %d / %d\n", r.Files-len(r.NoCopyright), r.Files)
+	fmt.Fprintf(w, "* Files with license information: %d / %d\n", r.Files-len(r.NoLicense), r.Files)
+	fmt.Fprintln(w)
+	if r.Compliant() {
+		fmt.Fprintf(w, "Congratulations! Your project is compliant with version %s of the REUSE Specification :-)\n", reuseVersion)
+	} else {
+		fmt.Fprintf(w, "Unfortunately, your project is not compliant with version %s of the REUSE Specification :-(\n", reuseVersion)
+	}
+}
@@ -0,0 +1,531 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxScanRequest is the largest body accepted by a request to start a scan.
+const maxScanRequest = 64 * 1024
+
+// ClassifyResult is the response to a request to classify a blob.
+type ClassifyResult struct {
+	Name string `json:"name"`
+	// License is the verdict on the blob's licenses, as printed in the text
+	// report, such as "MIT" or "Unknown!".
+	License  string    `json:"license"`
+	Licenses []License `json:"licenses"`
+}
+
+// ScanResult is the state of a scan of a path on the server, with its
+// report once it is done.
+type ScanResult struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+	// Status is "queued", "running", "done" or "failed".
+	Status   string     `json:"status"`
+	Error    string     `json:"error,omitempty"`
+	Created  time.Time  `json:"created"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Report   *Report    `json:"report,omitempty"`
+}
+
+// scanRequest is the body of a request to start a scan.
+type scanRequest struct {
+	Path  string `json:"path"`
+	Async bool   `json:"async"`
+}
+
+// server serves the HTTP API of `weasel serve`.
+type server struct {
+	root     string // Only paths in root may be scanned.
+	maxBlob  int64
+	keep     int           // How many finished scans are kept.
+	requests chan struct{} // Holds a token for each request being handled.
+	scans    chan struct{} // Holds a token for each scan queued or running.
+
+	// project is held by a scan while it has the project state loaded, such
+	// as the working directory, the overrides, and the classifier, which a
+	// project with custom licenses replaces while it is scanned, so scans
+	// run one at a time.
+	project sync.Mutex
+	// shared is the classifier state of no project, taken before any scan,
+	// which blobs are classified with, so they needn't wait for scans.
+	shared classifierState
+
+	lock    sync.Mutex // Guards the fields below.
+	results map[string]*ScanResult
+	order   []string // The ids of results, oldest first.
+	metrics serveMetrics
+}
+
+// serveMetrics are the counters reported by /metrics.
+type serveMetrics struct {
+	requests        map[[2]string]int64 // By endpoint and status code.
+	durations       map[string]float64  // Seconds, by endpoint.
+	durationCounts  map[string]int64
+	rejected        map[string]int64 // By reason.
+	scans           map[string]int64 // Finished scans, by status.
+	scansInProgress int64
+	classifiedBytes int64
+}
+
+// serveCommand serves an HTTP JSON API to classify blobs and scan
+// directories on the server.
+func serveCommand(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := flags.String("listen", "localhost:8080", "Address to listen on.")
+	root := flags.String("root", ".", "Only allow scans of directories in this directory.")
+	maxBlob := flags.Int64("max-blob", 10*1024*1024, "Largest blob accepted for classification, in bytes.")
+	maxRequests := flags.Int("max-requests", 16, "Most classification and scan requests handled at once.")
+	maxScans := flags.Int("max-scans", 4, "Most scans queued or running at once.")
+	keep := flags.Int("keep", 100, "How many finished scans to keep the results of.")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: weasel serve [-listen addr] [-root dir]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 0 || *maxRequests < 1 || *maxScans < 1 || *keep < 1 {
+		flags.Usage()
+		return 1
+	}
+
+	allowed, err := filepath.Abs(*root)
+	if err == nil {
+		allowed, err = filepath.EvalSymlinks(allowed)
+	}
+	if err != nil {
+		fmt.Println("Cannot read " + *root + ": " + err.Error())
+		return 1
+	}
+
+	s := &server{
+		root:     allowed,
+		maxBlob:  *maxBlob,
+		keep:     *keep,
+		requests: make(chan struct{}, *maxRequests),
+		scans:    make(chan struct{}, *maxScans),
+		results:  make(map[string]*ScanResult),
+		shared:   currentClassifier(),
+		metrics: serveMetrics{
+			requests:       make(map[[2]string]int64),
+			durations:      make(map[string]float64),
+			durationCounts: make(map[string]int64),
+			rejected:       make(map[string]int64),
+			scans:          make(map[string]int64),
+		},
+	}
+	srv := &http.Server{Addr: *listen, Handler: s.handler()}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving on %s, scanning in %s\n", *listen, allowed)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		fmt.Println("Cannot serve: " + err.Error())
+		return 1
+	}
+	return 0
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/classify", s.instrument("classify", s.limit(s.classify)))
+	mux.HandleFunc("/v1/scans", s.instrument("scans", s.limit(s.startScan)))
+	mux.HandleFunc("/v1/scans/", s.instrument("scan", s.getScan))
+	mux.HandleFunc("/metrics", s.instrument("metrics", s.writeMetrics))
+	return mux
+}
+
+// statusRecorder remembers the status code written to a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// instrument counts the requests to endpoint by status code, and the time
+// spent handling them.
+func (s *server) instrument(endpoint string, handle http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{w, http.StatusOK}
+		handle(rec, r)
+
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		s.metrics.requests[[2]string{endpoint, fmt.Sprint(rec.code)}]++
+		s.metrics.durations[endpoint] += time.Since(start).Seconds()
+		s.metrics.durationCounts[endpoint]++
+	}
+}
+
+// limit rejects requests while max-requests are already being handled.
+func (s *server) limit(handle http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case s.requests <- struct{}{}:
+			defer func() { <-s.requests }()
+			handle(w, r)
+		default:
+			s.reject("concurrency")
+			w.Header().Set("Retry-After", "1")
+			writeServeError(w, http.StatusTooManyRequests, "Too many requests at once")
+		}
+	}
+}
+
+func (s *server) reject(reason string) {
+	s.lock.Lock()
+	s.metrics.rejected[reason]++
+	s.lock.Unlock()
+}
+
+func writeServeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(``, `  `)
+	enc.Encode(v)
+}
+
+func writeServeError(w http.ResponseWriter, code int, msg string) {
+	writeServeJSON(w, code, map[string]string{"error": msg})
+}
+
+// classify classifies the body of a POST, as the contents of the file named
+// by the `name` query parameter, if any. Project overrides and inheritance
+// don't apply, as there is no project.
+func (s *server) classify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeServeError(w, http.StatusMethodNotAllowed, "Use POST")
+		return
+	}
+	if r.ContentLength > s.maxBlob {
+		s.reject("too_large")
+		writeServeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Blobs are limited to %d bytes", s.maxBlob))
+		return
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, s.maxBlob+1))
+	if err != nil {
+		writeServeError(w, http.StatusBadRequest, "Cannot read blob: "+err.Error())
+		return
+	}
+	if int64(len(b)) > s.maxBlob {
+		s.reject("too_large")
+		writeServeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("Blobs are limited to %d bytes", s.maxBlob))
+		return
+	}
+
+	name := path.Base(r.URL.Query().Get("name"))
+	if name == `.` || name == `/` {
+		name = `blob`
+	}
+	lics, err := s.shared.contentLicenses(name, b)
+	if err != nil {
+		writeServeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.lock.Lock()
+	s.metrics.classifiedBytes += int64(len(b))
+	s.lock.Unlock()
+
+	licStr, _, _ := verdict(lics)
+	if lics == nil {
+		lics = []License{}
+	}
+	writeServeJSON(w, http.StatusOK, ClassifyResult{Name: name, License: licStr, Licenses: lics})
+}
+
+// startScan starts a scan of the directory named in the request, and
+// responds with its report, or with its id at once if it is async.
+func (s *server) startScan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeServeError(w, http.StatusMethodNotAllowed, "Use POST")
+		return
+	}
+	var req scanRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxScanRequest)).Decode(&req); err != nil {
+		writeServeError(w, http.StatusBadRequest, "Malformed scan request: "+err.Error())
+		return
+	}
+	dir, code, err := s.scanDir(req.Path)
+	if err != nil {
+		writeServeError(w, code, err.Error())
+		return
+	}
+
+	select {
+	case s.scans <- struct{}{}:
+	default:
+		s.reject("scan_queue")
+		w.Header().Set("Retry-After", "10")
+		writeServeError(w, http.StatusTooManyRequests, "Too many scans queued")
+		return
+	}
+
+	result := &ScanResult{ID: newScanID(), Path: dir, Status: "queued", Created: time.Now()}
+	s.lock.Lock()
+	s.results[result.ID] = result
+	s.order = append(s.order, result.ID)
+	s.metrics.scansInProgress++
+	s.lock.Unlock()
+
+	if req.Async {
+		go s.runScan(result)
+		w.Header().Set("Location", "/v1/scans/"+result.ID)
+		writeServeJSON(w, http.StatusAccepted, s.result(result.ID))
+		return
+	}
+	s.runScan(result)
+	code = http.StatusOK
+	if res := s.result(result.ID); res.Status == "failed" {
+		code = http.StatusInternalServerError
+	}
+	writeServeJSON(w, code, s.result(result.ID))
+}
+
+// scanDir resolves p to a directory in the server's root, returning the
+// status code to respond with if it isn't one.
+func (s *server) scanDir(p string) (string, int, error) {
+	if p == `` {
+		return ``, http.StatusBadRequest, fmt.Errorf("No path to scan")
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(s.root, p)
+	}
+	dir, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return ``, http.StatusNotFound, fmt.Errorf("Cannot read %s", p)
+	}
+	rel, err := filepath.Rel(s.root, dir)
+	if err != nil || rel == `..` || strings.HasPrefix(rel, `..`+string(filepath.Separator)) {
+		return ``, http.StatusForbidden, fmt.Errorf("%s is not in %s", p, s.root)
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return ``, http.StatusBadRequest, fmt.Errorf("%s is not a directory", p)
+	}
+	return dir, 0, nil
+}
+
+// runScan scans result's directory, one project at a time, and records the
+// report.
+func (s *server) runScan(result *ScanResult) {
+	defer func() { <-s.scans }()
+
+	s.project.Lock()
+	s.setStatus(result, "running", nil, nil)
+	report, err := scanProject(result.Path)
+	s.project.Unlock()
+
+	if err != nil {
+		s.setStatus(result, "failed", err, nil)
+	} else {
+		s.setStatus(result, "done", nil, &report)
+	}
+}
+
+func (s *server) setStatus(result *ScanResult, status string, err error, report *Report) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	result.Status = status
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.Report = report
+	if status != "done" && status != "failed" {
+		return
+	}
+	now := time.Now()
+	result.Finished = &now
+	s.metrics.scansInProgress--
+	s.metrics.scans[status]++
+
+	// Forget the oldest finished scans beyond those to keep.
+	finished := 0
+	for i := len(s.order) - 1; i >= 0; i-- {
+		if r := s.results[s.order[i]]; r.Finished != nil {
+			finished++
+			if finished > s.keep {
+				delete(s.results, r.ID)
+				s.order = append(s.order[:i], s.order[i+1:]...)
+			}
+		}
+	}
+}
+
+// scanProject scans the project in dir as weasel does, restoring the working
+// directory and the shared classifier afterward. The caller must hold the
+// project lock.
+func scanProject(dir string) (Report, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return Report{}, fmt.Errorf("Unable to get working directory: %v", err)
+	}
+	shared := currentClassifier()
+	defer func() {
+		os.Chdir(wd)
+		setClassifier(shared)
+		cleanupGit()
+		tmpGitDir = ``
+	}()
+
+	// The working directory is the whole process's, but only scans use it:
+	// they hold the project lock, so only one changes it at a time, and
+	// blobs are classified from their request bodies, with the shared
+	// classifier state, without reading any file.
+	if err := os.Chdir(dir); err != nil {
+		return Report{}, fmt.Errorf("Failed to enter target directory: %v!", err)
+	}
+	initGit()
+	if err := reloadProject(); err != nil {
+		return Report{}, err
+	}
+	files, copyrights, err := scan(`.`)
+	if err != nil {
+		return Report{}, err
+	}
+	return buildReport(dir, files, copyrights), nil
+}
+
+// result returns a copy of the scan with the given id, or nil if there is
+// none.
+func (s *server) result(id string) *ScanResult {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	r, ok := s.results[id]
+	if !ok {
+		return nil
+	}
+	copied := *r
+	return &copied
+}
+
+// getScan responds with the state of the scan whose id ends the path.
+func (s *server) getScan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeServeError(w, http.StatusMethodNotAllowed, "Use GET")
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/v1/scans/")
+	result := s.result(id)
+	if result == nil {
+		writeServeError(w, http.StatusNotFound, "No scan "+id)
+		return
+	}
+	writeServeJSON(w, http.StatusOK, result)
+}
+
+func newScanID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// writeMetrics writes the metrics in the Prometheus text format.
+func (s *server) writeMetrics(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	m := s.metrics
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintln(w, "# HELP weasel_http_requests_total HTTP requests handled, by endpoint and status code.")
+	fmt.Fprintln(w, "# TYPE weasel_http_requests_total counter")
+	var keys [][2]string
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "weasel_http_requests_total{endpoint=%q,code=%q} %d\n", k[0], k[1], m.requests[k])
+	}
+
+	fmt.Fprintln(w, "# HELP weasel_http_request_duration_seconds Time spent handling HTTP requests, by endpoint.")
+	fmt.Fprintln(w, "# TYPE weasel_http_request_duration_seconds summary")
+	for _, endpoint := range sortedCounts(m.durationCounts) {
+		fmt.Fprintf(w, "weasel_http_request_duration_seconds_sum{endpoint=%q} %g\n", endpoint, m.durations[endpoint])
+		fmt.Fprintf(w, "weasel_http_request_duration_seconds_count{endpoint=%q} %d\n", endpoint, m.durationCounts[endpoint])
+	}
+
+	fmt.Fprintln(w, "# HELP weasel_rejected_requests_total Requests rejected by a limit, by reason.")
+	fmt.Fprintln(w, "# TYPE weasel_rejected_requests_total counter")
+	for _, reason := range sortedCounts(m.rejected) {
+		fmt.Fprintf(w, "weasel_rejected_requests_total{reason=%q} %d\n", reason, m.rejected[reason])
+	}
+
+	fmt.Fprintln(w, "# HELP weasel_scans_total Finished scans, by status.")
+	fmt.Fprintln(w, "# TYPE weasel_scans_total counter")
+	for _, status := range sortedCounts(m.scans) {
+		fmt.Fprintf(w, "weasel_scans_total{status=%q} %d\n", status, m.scans[status])
+	}
+
+	fmt.Fprintln(w, "# HELP weasel_scans_in_progress Scans queued or running.")
+	fmt.Fprintln(w, "# TYPE weasel_scans_in_progress gauge")
+	fmt.Fprintf(w, "weasel_scans_in_progress %d\n", m.scansInProgress)
+
+	fmt.Fprintln(w, "# HELP weasel_classified_bytes_total Bytes of blobs classified.")
+	fmt.Fprintln(w, "# TYPE weasel_classified_bytes_total counter")
+	fmt.Fprintf(w, "weasel_classified_bytes_total %d\n", m.classifiedBytes)
+}
+
+func sortedCounts(m map[string]int64) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// timings are the nanoseconds spent in each of the slow parts of a scan,
+// summed across workers.
+var timings struct {
+	Git        int64 // Running git, mostly to check for ignored files.
+	File       int64 // Running `file` to tell the kind of files without licenses.
+	Classifier int64
+}
+
+// spent adds the time since start to the timing t, as in
+// `defer spent(&timings.Git, time.Now())`.
+func spent(t *int64, start time.Time) {
+	atomic.AddInt64(t, int64(time.Since(start)))
+}
+
+// scanProgress counts the files found and classified by scanWith, as it
+// runs.
+type scanProgress struct {
+	Discovered int64
+	Classified int64
+	Ignored    int64        // Files ignored by .gitignore.
+	Large      int64        // Files over large.limit, not classified whole.
+	Current    atomic.Value // The path of the file last classified.
+}
+
+// showProgress writes the progress of a scan over a single line of w, until
+// stop is closed, and then clears it.
+func showProgress(w io.Writer, p *scanProgress, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	start := time.Now()
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			fmt.Fprint(w, "\r\x1b[K")
+			return
+		case <-ticker.C:
+		}
+
+		classified := atomic.LoadInt64(&p.Classified)
+		rate := float64(classified) / time.Since(start).Seconds()
+		current, _ := p.Current.Load().(string)
+		if len(current) > 50 {
+			current = `...` + current[len(current)-47:]
+		}
+		fmt.Fprintf(w, "\r\x1b[K%d/%d files, %.0f files/s %s", classified, atomic.LoadInt64(&p.Discovered), rate, current)
+	}
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// ScanSummary counts the results of a scan.
+type ScanSummary struct {
+	// Files are the files classified, including those ignored by overrides.
+	Files int `json:"files"`
+	// Licenses are the number of files with each license, other than
+	// ignored files.
+	Licenses []SummaryCount `json:"licenses"`
+	// Failures are the number of failing files by why they fail:
+	// "Undocumented", "Unknown", "Too-Large" or "Error", and the number of
+	// other problems by their category.
+	Failures []SummaryCount `json:"failures"`
+	// Ignored are the files ignored by .gitignore or overrides.
+	Ignored int `json:"ignored"`
+	// Large are the files over large.limit, which weren't classified whole,
+	// and TooLarge those of them in which no license was found.
+	Large    int `json:"large"`
+	TooLarge int `json:"tooLarge"`
+	// Seconds are the time taken by the whole scan, and that spent in git,
+	// `file` and the classifier, summed across workers.
+	Seconds SummaryTimes `json:"seconds"`
+}
+
+// SummaryCount is the number of files in a category of a summary.
+type SummaryCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// SummaryTimes are the times in a summary.
+type SummaryTimes struct {
+	Total      float64 `json:"total"`
+	Git        float64 `json:"git"`
+	File       float64 `json:"file"`
+	Classifier float64 `json:"classifier"`
+}
+
+// summarize counts the results in a report of a scan that took elapsed, with
+// the files p counted as ignored by .gitignore or large.
+func summarize(r Report, p *scanProgress, elapsed time.Duration) *ScanSummary {
+	s := &ScanSummary{Ignored: int(atomic.LoadInt64(&p.Ignored)), Large: int(atomic.LoadInt64(&p.Large))}
+	licenses := make(map[string]int)
+	failures := make(map[string]int)
+	for _, f := range r.Files {
+		s.Files++
+		if f.Ignored {
+			s.Ignored++
+			continue
+		}
+		ids := make(map[string]bool)
+		for _, lic := range f.Licenses {
+			ids[summaryID(lic)] = true
+		}
+		if len(f.Licenses) == 0 {
+			ids[`Unknown`] = true
+		}
+		for id := range ids {
+			licenses[id]++
+		}
+		if ids[string(tooLarge)] {
+			s.TooLarge++
+		}
+		if f.Error {
+			failures[failureCategory(f.Licenses)]++
+		}
+	}
+	for _, p := range r.Problems {
+		failures[strings.TrimSuffix(p.Category, `!`)]++
+	}
+
+	s.Licenses, s.Failures = summaryCounts(licenses), summaryCounts(failures)
+	s.Seconds = SummaryTimes{
+		Total:      elapsed.Seconds(),
+		Git:        time.Duration(atomic.LoadInt64(&timings.Git)).Seconds(),
+		File:       time.Duration(atomic.LoadInt64(&timings.File)).Seconds(),
+		Classifier: time.Duration(atomic.LoadInt64(&timings.Classifier)).Seconds(),
+	}
+	return s
+}
+
+// summaryID is the id a license is counted under, without its markers, and
+// with the reason for errors left out.
+func summaryID(lic License) string {
+	id := licenseID(lic)
+	switch {
+	case strings.HasPrefix(id, `Error: `):
+		return `Error`
+	case strings.HasPrefix(id, `Unknown`):
+		return `Unknown`
+	}
+	return id
+}
+
+// failureCategory is why a file with licenses fails.
+func failureCategory(lics []License) string {
+	if len(lics) == 0 {
+		return `Unknown`
+	}
+	for _, lic := range lics {
+		switch id := summaryID(lic); id {
+		case `Unknown`, `Error`, string(tooLarge):
+			return id
+		}
+	}
+	return `Undocumented`
+}
+
+// summaryCounts returns counts, most common first.
+func summaryCounts(counts map[string]int) []SummaryCount {
+	list := []SummaryCount{}
+	for name, count := range counts {
+		list = append(list, SummaryCount{name, count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// writeSummaryText writes a summary as a table.
+func writeSummaryText(w io.Writer, s *ScanSummary) {
+	fmt.Fprintf(w, "%-10s%36s %d\n", "Files", "", s.Files)
+	for _, c := range s.Licenses {
+		fmt.Fprintf(w, "%-10s%36s %d\n", "License", c.Name, c.Count)
+	}
+	for _, c := range s.Failures {
+		fmt.Fprintf(w, "%-10s%36s %d\n", "Failure", c.Name, c.Count)
+	}
+	fmt.Fprintf(w, "%-10s%36s %d\n", "Ignored", "", s.Ignored)
+	fmt.Fprintf(w, "%-10s%36s %d\n", "Large", "", s.Large)
+	fmt.Fprintf(w, "%-10s%36s %d\n", "Too-Large", "", s.TooLarge)
+	fmt.Fprintf(w, "%-10s%36s %.2fs\n", "Time", "git", s.Seconds.Git)
+	fmt.Fprintf(w, "%-10s%36s %.2fs\n", "Time", "file", s.Seconds.File)
+	fmt.Fprintf(w, "%-10s%36s %.2fs\n", "Time", "classifier", s.Seconds.Classifier)
+	fmt.Fprintf(w, "%-10s%36s %.2fs\n", "Time", "total", s.Seconds.Total)
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML parses the subset of TOML used by REUSE.toml: tables, arrays of
+// tables, and keys whose values are strings, integers, booleans or arrays of
+// them. Dotted keys, inline tables, multi-line strings and dates aren't
+// supported. Tables are returned as map[string]interface{}, and arrays as
+// []interface{}.
+func parseTOML(text string) (map[string]interface{}, error) {
+	p := &tomlParser{text: text, line: 1}
+	root := make(map[string]interface{})
+	table := root
+	for {
+		p.skip(true)
+		if p.pos >= len(p.text) {
+			return root, nil
+		}
+
+		if p.text[p.pos] == '[' {
+			array := strings.HasPrefix(p.text[p.pos:], `[[`)
+			end := strings.Index(p.text[p.pos:], "]")
+			if end < 0 {
+				return nil, p.errorf("unterminated table header")
+			}
+			start := p.pos + 1
+			if array {
+				start++
+			}
+			name := strings.TrimSpace(p.text[start : p.pos+end])
+			p.pos += end + 1
+			if array {
+				if !strings.HasPrefix(p.text[p.pos:], `]`) {
+					return nil, p.errorf("unterminated table header")
+				}
+				p.pos++
+			}
+
+			table = make(map[string]interface{})
+			if array {
+				tables, _ := root[name].([]interface{})
+				root[name] = append(tables, table)
+			} else if _, ok := root[name]; ok {
+				return nil, p.errorf("duplicate table %s", name)
+			} else {
+				root[name] = table
+			}
+			if err := p.endOfLine(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		p.skip(false)
+		if p.pos >= len(p.text) || p.text[p.pos] != '=' {
+			return nil, p.errorf("expected = after %s", key)
+		}
+		p.pos++
+		p.skip(false)
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := table[key]; ok {
+			return nil, p.errorf("duplicate key %s", key)
+		}
+		table[key] = value
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+type tomlParser struct {
+	text string
+	pos  int
+	line int
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// skip skips whitespace and comments, and newlines if newlines is true.
+func (p *tomlParser) skip(newlines bool) {
+	for p.pos < len(p.text) {
+		switch c := p.text[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		case c == '#':
+			for p.pos < len(p.text) && p.text[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) endOfLine() error {
+	p.skip(false)
+	if p.pos < len(p.text) && p.text[p.pos] != '\n' {
+		return p.errorf("unexpected %q", p.text[p.pos:p.pos+1])
+	}
+	return nil
+}
+
+func (p *tomlParser) key() (string, error) {
+	if p.pos < len(p.text) && (p.text[p.pos] == '"' || p.text[p.pos] == '\'') {
+		return p.str()
+	}
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		if !(c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			break
+		}
+		p.pos++
+	}
+	if start == p.pos {
+		return ``, p.errorf("expected a key")
+	}
+	return p.text[start:p.pos], nil
+}
+
+func (p *tomlParser) value() (interface{}, error) {
+	if p.pos >= len(p.text) {
+		return nil, p.errorf("expected a value")
+	}
+	switch c := p.text[p.pos]; {
+	case c == '"' || c == '\'':
+		return p.str()
+	case c == '[':
+		p.pos++
+		values := []interface{}{}
+		for {
+			p.skip(true)
+			if p.pos < len(p.text) && p.text[p.pos] == ']' {
+				p.pos++
+				return values, nil
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+			p.skip(true)
+			if p.pos < len(p.text) && p.text[p.pos] == ',' {
+				p.pos++
+			} else if p.pos >= len(p.text) || p.text[p.pos] != ']' {
+				return nil, p.errorf("expected , or ] in array")
+			}
+		}
+	}
+
+	start := p.pos
+	for p.pos < len(p.text) && !strings.ContainsRune(" \t\r\n,]#", rune(p.text[p.pos])) {
+		p.pos++
+	}
+	word := p.text[start:p.pos]
+	switch word {
+	case `true`:
+		return true, nil
+	case `false`:
+		return false, nil
+	}
+	n, err := strconv.ParseInt(strings.Replace(word, `_`, ``, -1), 0, 64)
+	if err != nil {
+		return nil, p.errorf("unsupported value %q", word)
+	}
+	return n, nil
+}
+
+// str parses a basic or literal string on a single line.
+func (p *tomlParser) str() (string, error) {
+	quote := p.text[p.pos]
+	if strings.HasPrefix(p.text[p.pos:], strings.Repeat(string(quote), 3)) {
+		return ``, p.errorf("multi-line strings are not supported")
+	}
+	for end := p.pos + 1; end < len(p.text) && p.text[end] != '\n'; end++ {
+		switch p.text[end] {
+		case '\\':
+			if quote == '"' {
+				end++
+			}
+		case quote:
+			raw := p.text[p.pos : end+1]
+			p.pos = end + 1
+			if quote == '\'' {
+				return raw[1 : len(raw)-1], nil
+			}
+			s, err := strconv.Unquote(raw)
+			if err != nil {
+				return ``, p.errorf("bad string %s", raw)
+			}
+			return s, nil
+		}
+	}
+	return ``, p.errorf("unterminated string")
+}
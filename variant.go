@@ -0,0 +1,291 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/google/licenseclassifier"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// variantThreshold is the lowest classifier confidence at which a license
+// file that matches no license is compared against its nearest match.
+const variantThreshold = 0.5
+
+// variantSuffix is appended to the id of a license whose text was changed
+// substantively, such as `MIT-variant`. Variants are not in the license
+// database, so they are never accepted and always need documenting.
+const variantSuffix = `-variant`
+
+// maxReplacement is the most words that may replace placeholder text, such
+// as `<copyright holders>`, in a license.
+const maxReplacement = 8
+
+// maxOmittable is the most words that may be missing from the start or end of
+// a license, such as its title or a copyright line.
+const maxOmittable = 12
+
+// replaceableWords are the words of the placeholders that the SPDX matching
+// guidelines allow to be replaced, such as the copyright holder in the body
+// of the BSD licenses.
+var replaceableWords = map[string]bool{
+	`author`: true, `authors`: true, `c`: true, `contributors`: true,
+	`copyright`: true, `holder`: true, `holders`: true, `name`: true,
+	`of`: true, `organization`: true, `owner`: true, `owners`: true,
+	`the`: true, `year`: true, `yyyy`: true,
+}
+
+// Change is a difference between a file and the text of the license it
+// resembles, with a few words of context on either side.
+type Change struct {
+	Before   string
+	Deleted  string
+	Inserted string
+	After    string
+}
+
+// String shows a change in the style of `git diff --word-diff`.
+func (c Change) String() string {
+	var parts []string
+	if c.Before != `` {
+		parts = append(parts, `...`+c.Before)
+	}
+	if c.Deleted != `` {
+		parts = append(parts, `[-`+c.Deleted+`-]`)
+	}
+	if c.Inserted != `` {
+		parts = append(parts, `{+`+c.Inserted+`+}`)
+	}
+	if c.After != `` {
+		parts = append(parts, c.After+`...`)
+	}
+	return strings.Join(parts, ` `)
+}
+
+// Variant describes how a file differs from the text of a known license.
+// Differences before and after the license, and within replaceable text,
+// aren't substantive, and aren't included in Changes.
+type Variant struct {
+	// License is the id of the license, or of its standard header with a
+	// `.header` suffix.
+	License string
+	Changes []Change
+}
+
+// variantLicense returns lic, or its variant if the text it was matched in
+// changes the license substantively.
+func (c classifierState) variantLicense(lic License, confidence float64, text string) License {
+	if confidence >= 1 {
+		return lic
+	}
+	if v := c.findVariant(string(lic), text); v != nil && len(v.Changes) != 0 {
+		return License(string(lic) + variantSuffix)
+	}
+	return lic
+}
+
+// nearClassifier is a classifier with a lower threshold, built from archive
+// the first time it is needed.
+type nearClassifier struct {
+	archive    []byte
+	once       sync.Once
+	classifier *licenseclassifier.License
+	err        error
+}
+
+func newNearClassifier(archive []byte) *nearClassifier {
+	return &nearClassifier{archive: archive}
+}
+
+// get returns the classifier, building it if it hasn't been.
+func (n *nearClassifier) get() (*licenseclassifier.License, error) {
+	n.once.Do(func() {
+		n.classifier, n.err = licenseclassifier.New(variantThreshold, licenseclassifier.ArchiveBytes(n.archive))
+	})
+	return n.classifier, n.err
+}
+
+// nearestVariant compares text with its nearest match among the licenses the
+// classifier was built from, for a license file that matched none. It returns
+// nil if nothing is near enough. The classifier's own NearestMatch skips
+// licenses much longer or shorter than text, so it misses variants with
+// clauses deleted; a nearClassifier finds them.
+func nearestVariant(text string) (*Variant, float64) {
+	return currentClassifier().nearestVariant(text)
+}
+
+// nearestVariant is nearestVariant, with the classifier state c.
+func (c classifierState) nearestVariant(text string) (*Variant, float64) {
+	near, err := c.near.get()
+	if err != nil {
+		return nil, 0
+	}
+	matches := near.MultipleMatch(text, true)
+	if len(matches) == 0 || matches[0] == nil {
+		return nil, 0
+	}
+	return c.findVariant(matches[0].Name, text), matches[0].Confidence
+}
+
+// findVariant compares text with the license with the given id, and with its
+// standard header, and returns the closer of the two. It returns nil if the
+// license's text isn't known.
+func findVariant(id string, text string) *Variant {
+	return currentClassifier().findVariant(id, text)
+}
+
+// findVariant is findVariant, with the license texts of the classifier state
+// c.
+func (c classifierState) findVariant(id string, text string) *Variant {
+	words := normalizedWords(text)
+	var best *Variant
+	for _, candidate := range []string{id, id + `.header`} {
+		canonical, ok := c.licenseText(candidate)
+		if !ok {
+			continue
+		}
+		v := &Variant{
+			License: candidate,
+			Changes: substantiveChanges(wordDiff(normalizedWords(licenseclassifier.TrimExtraneousTrailingText(canonical)), words)),
+		}
+		if best == nil || len(v.Changes) < len(best.Changes) {
+			best = v
+		}
+	}
+	return best
+}
+
+// normalizedWords normalizes text the way the classifier does, and splits it
+// into words.
+func normalizedWords(text string) []string {
+	for _, n := range licenseclassifier.Normalizers {
+		text = n(text)
+	}
+	return strings.Fields(text)
+}
+
+// wordDiff returns the differences between two lists of words, one word per
+// line of each diff's text.
+func wordDiff(from []string, to []string) []diffmatchpatch.Diff {
+	dmp := diffmatchpatch.New()
+	dmp.DiffTimeout = 0
+	a, b, words := dmp.DiffLinesToRunes(lines(from), lines(to))
+	diffs := dmp.DiffMainRunes(a, b, false)
+	diffs = dmp.DiffCleanupSemantic(diffs)
+	return dmp.DiffCharsToLines(diffs, words)
+}
+
+func lines(words []string) string {
+	if len(words) == 0 {
+		return ``
+	}
+	return strings.Join(words, "\n") + "\n"
+}
+
+// substantiveChanges groups the differences between the texts into changes,
+// and drops those that only replace placeholder text. Text in the file before
+// and after the license is ignored, as are up to maxOmittable words missing
+// from the start or end of the license, such as its title.
+func substantiveChanges(diffs []diffmatchpatch.Diff) []Change {
+	first, last := -1, -1
+	for i, d := range diffs {
+		if d.Type == diffmatchpatch.DiffEqual {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+
+	var changes []Change
+	if deleted := deletedWords(diffs[:first]); len(deleted) > maxOmittable {
+		changes = append(changes, newChange(nil, deleted, nil, diffs[first].Text))
+	}
+	for i := first + 1; i < last; i++ {
+		if diffs[i].Type == diffmatchpatch.DiffEqual {
+			continue
+		}
+		before := diffs[i-1].Text
+		var deleted, inserted []string
+		for ; diffs[i].Type != diffmatchpatch.DiffEqual; i++ {
+			if diffs[i].Type == diffmatchpatch.DiffDelete {
+				deleted = append(deleted, strings.Fields(diffs[i].Text)...)
+			} else {
+				inserted = append(inserted, strings.Fields(diffs[i].Text)...)
+			}
+		}
+		if !replaceable(deleted, inserted) {
+			changes = append(changes, newChange(strings.Fields(before), deleted, inserted, diffs[i].Text))
+		}
+	}
+	if deleted := deletedWords(diffs[last+1:]); len(deleted) > maxOmittable {
+		changes = append(changes, newChange(strings.Fields(diffs[last].Text), deleted, nil, ``))
+	}
+	return changes
+}
+
+// newChange returns a change with a few words of the text before and after it
+// as context.
+func newChange(before []string, deleted []string, inserted []string, after string) Change {
+	const context = 4
+	if len(before) > context {
+		before = before[len(before)-context:]
+	}
+	afterWords := strings.Fields(after)
+	if len(afterWords) > context {
+		afterWords = afterWords[:context]
+	}
+	return Change{
+		Before:   strings.Join(before, ` `),
+		Deleted:  strings.Join(deleted, ` `),
+		Inserted: strings.Join(inserted, ` `),
+		After:    strings.Join(afterWords, ` `),
+	}
+}
+
+// deletedWords returns the words of the license missing from the file in
+// diffs.
+func deletedWords(diffs []diffmatchpatch.Diff) []string {
+	var words []string
+	for _, d := range diffs {
+		if d.Type == diffmatchpatch.DiffDelete {
+			words = append(words, strings.Fields(d.Text)...)
+		}
+	}
+	return words
+}
+
+// replaceable reports whether a change only replaces placeholder text, such
+// as `the copyright holder` with the name of an organization.
+func replaceable(deleted []string, inserted []string) bool {
+	if len(deleted) == 0 || len(inserted) > maxReplacement {
+		return false
+	}
+	for _, word := range deleted {
+		if !replaceableWords[word] {
+			return false
+		}
+	}
+	return true
+}
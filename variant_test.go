@@ -0,0 +1,130 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubstantiveChanges(t *testing.T) {
+	license := `permission is granted to any person provided that the copyright holder is credited and this notice is kept in all copies`
+	tests := []struct {
+		name string
+		text string
+		want []Change
+	}{
+		{
+			name: "same",
+			text: license,
+		},
+		{
+			name: "placeholder replaced",
+			text: `permission is granted to any person provided that acme corp is credited and this notice is kept in all copies`,
+		},
+		{
+			name: "text around the license",
+			text: `my project readme ` + license + ` see also the docs`,
+		},
+		{
+			name: "clause changed",
+			text: `permission is granted to any person provided that the copyright holder is paid and this notice is kept in all copies`,
+			want: []Change{{Before: `the copyright holder is`, Deleted: `credited`, Inserted: `paid`, After: `and this notice is`}},
+		},
+		{
+			name: "placeholder replaced with too many words",
+			text: `permission is granted to any person provided that one two three four five six seven eight nine is credited and this notice is kept in all copies`,
+			want: []Change{{Before: `any person provided that`, Deleted: `the copyright holder`, Inserted: `one two three four five six seven eight nine`, After: `is credited and this`}},
+		},
+		{
+			name: "words inserted",
+			text: `permission is granted to any person provided that the copyright holder is credited and this notice is never kept in all copies`,
+			want: []Change{{Before: `and this notice is`, Inserted: `never`, After: `kept in all copies`}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := substantiveChanges(wordDiff(strings.Fields(license), strings.Fields(test.text)))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSubstantiveChangesOmitted(t *testing.T) {
+	license := strings.Fields(`the title of the license then permission is granted to any person provided that this notice is kept in all copies of the software and no warranty is given by anyone at all ever`)
+
+	// A few words missing from the start or end, such as the title, don't
+	// change the license.
+	if got := substantiveChanges(wordDiff(license, license[5:])); len(got) != 0 {
+		t.Errorf("title omitted: got %+v, want no changes", got)
+	}
+	// Many do.
+	if got := substantiveChanges(wordDiff(license, license[:len(license)-maxOmittable-1])); len(got) != 1 || got[0].Deleted == `` {
+		t.Errorf("end omitted: got %+v, want a deletion", got)
+	}
+}
+
+func TestChangeString(t *testing.T) {
+	tests := []struct {
+		change Change
+		want   string
+	}{
+		{Change{Before: `a b`, Deleted: `c`, Inserted: `d`, After: `e f`}, `...a b [-c-] {+d+} e f...`},
+		{Change{Inserted: `d`, After: `e`}, `{+d+} e...`},
+		{Change{Before: `a`, Deleted: `c`}, `...a [-c-]`},
+	}
+
+	for _, test := range tests {
+		if got := test.change.String(); got != test.want {
+			t.Errorf("%+v.String() = %q, want %q", test.change, got, test.want)
+		}
+	}
+}
+
+func TestVariantLicense(t *testing.T) {
+	mit, ok := builtinClassifier.licenseText(`MIT`)
+	if !ok {
+		t.Skip("the license database has no MIT text")
+	}
+	c := builtinClassifier
+	holder := strings.Replace(mit, `<copyright holders>`, `Acme Corp`, -1)
+	changed := strings.Replace(mit, `free of charge`, `for a fee`, 1)
+
+	if got := c.variantLicense(`MIT`, 1, changed); got != `MIT` {
+		t.Errorf("variantLicense of a certain match = %q, want MIT", got)
+	}
+	if got := c.variantLicense(`MIT`, 0.95, holder); got != `MIT` {
+		t.Errorf("variantLicense with the holder filled in = %q, want MIT", got)
+	}
+	if got := c.variantLicense(`MIT`, 0.95, changed); got != `MIT`+variantSuffix {
+		t.Errorf("variantLicense with a clause changed = %q, want MIT%s", got, variantSuffix)
+	}
+
+	v := c.findVariant(`MIT`, changed)
+	if v == nil || v.License != `MIT` || len(v.Changes) != 1 || !strings.Contains(v.Changes[0].Inserted, `fee`) {
+		t.Errorf("findVariant = %+v, want one change inserting the fee", v)
+	}
+	if v := c.findVariant(`No-Such-License`, changed); v != nil {
+		t.Errorf("findVariant of an unknown license = %+v, want nil", v)
+	}
+}
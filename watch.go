@@ -0,0 +1,356 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// watchDebounce is how long watch waits for changes to stop before checking
+// them, so that a burst of writes, such as a vendoring tool's, is checked at
+// once.
+const watchDebounce = 200 * time.Millisecond
+
+// fileWatcher reports the paths of files and directories that are created,
+// written, removed or renamed under the project root, relative to it.
+type fileWatcher interface {
+	Events() <-chan string
+	Errors() <-chan error
+}
+
+// projectFile reports whether name changes how other files are checked, so
+// the project must be reloaded and every file checked again when it changes.
+func projectFile(name string) bool {
+	switch name {
+	case reuseTOMLFile, reuseDep5File, debianCopyrightFile:
+		return true
+	}
+	// Any LICENSE or configFile may make its directory a nested project.
+	switch path.Base(name) {
+	case configFile, `LICENSE`, `.gitignore`:
+		return true
+	}
+	return strings.HasSuffix(name, `.dependency_license`) ||
+		strings.Contains(name, `.dependency_licenses/`) || strings.HasPrefix(name, customLicenseDir+`/`)
+}
+
+// reloadProject is loadProject for long-running commands, which must
+// survive a project file that is malformed while it is being edited.
+func reloadProject() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return loadProject()
+}
+
+// watchVerdict is the verdict on a file's licenses, as printed in the report.
+type watchVerdict struct {
+	License string
+	Failed  bool
+}
+
+// watchResult is the result of reading a file's contents with fileLicenses.
+type watchResult struct {
+	Licenses []License
+	Err      error
+}
+
+// watchState remembers the classification of every file, so that only the
+// files that change need to be read again.
+type watchState struct {
+	contents map[string]watchResult // By the file read, which may be a sidecar.
+	ignored  map[string]bool
+	kinds    map[string]string
+	raw      map[string][]License // The result of classify, by file.
+	verdicts map[string]watchVerdict
+	lock     sync.Mutex // Guards contents.
+}
+
+// watchCommand checks the project, then checks the files that change as they
+// change, printing the files that start or stop failing.
+func watchCommand(args []string) int {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: weasel watch [dir]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	target := `.`
+	if flags.NArg() > 0 {
+		target = flags.Arg(0)
+	}
+	if _, err := enterProject(target); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	if err := loadProject(); err != nil {
+		fmt.Println(err.Error())
+		return 1
+	}
+	root, err := os.Getwd()
+	if err != nil {
+		fmt.Println("Unable to get working directory: " + err.Error())
+		return 1
+	}
+
+	// Start watching before the first check, so no change is missed.
+	watcher, err := newFileWatcher(root)
+	if err != nil {
+		fmt.Println("Cannot watch " + root + ": " + err.Error())
+		return 1
+	}
+
+	s := &watchState{
+		contents: make(map[string]watchResult),
+		ignored:  make(map[string]bool),
+		kinds:    make(map[string]string),
+		raw:      make(map[string][]License),
+	}
+	s.reclassify(s.walk(`.`))
+	s.verdicts = s.evaluate()
+
+	failing := 0
+	for _, name := range sortedVerdicts(s.verdicts) {
+		if v := s.verdicts[name]; v.Failed {
+			fmt.Printf("%-6s%40s %s\n", "Error", v.License, name)
+			failing++
+		}
+	}
+	fmt.Printf("Watching %s: %d files, %d failing\n", root, len(s.verdicts), failing)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	changed := make(map[string]bool)
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-interrupt:
+			return 0
+		case err := <-watcher.Errors():
+			fmt.Fprintln(os.Stderr, err.Error())
+		case name := <-watcher.Events():
+			changed[name] = true
+			debounce = time.After(watchDebounce)
+		case <-debounce:
+			s.update(os.Stdout, changed)
+			changed = make(map[string]bool)
+			debounce = nil
+		}
+	}
+}
+
+// walk returns the files under dir.
+func (s *watchState) walk(dir string) []string {
+	var names []string
+	filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if filepath.Base(name) == `.git` {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names
+}
+
+// isIgnored reports whether name is ignored, remembering the answer until a
+// .gitignore file changes.
+func (s *watchState) isIgnored(name string) bool {
+	ignored, ok := s.ignored[name]
+	if !ok {
+		ignored = Ignored(name)
+		s.ignored[name] = ignored
+	}
+	return ignored
+}
+
+// kind returns the filekind of name, remembering it until the file changes.
+func (s *watchState) kind(name string) string {
+	kind, ok := s.kinds[name]
+	if !ok {
+		kind = filekind(name)
+		s.kinds[name] = kind
+	}
+	return kind
+}
+
+// readLicenses is fileLicenses, remembering the result until the file
+// changes.
+func (s *watchState) readLicenses(source string) ([]License, error) {
+	s.lock.Lock()
+	result, ok := s.contents[source]
+	s.lock.Unlock()
+	if ok {
+		return result.Licenses, result.Err
+	}
+
+	lics, err := fileLicenses(source)
+	s.lock.Lock()
+	s.contents[source] = watchResult{lics, err}
+	s.lock.Unlock()
+	return lics, err
+}
+
+// reclassify classifies names again, forgetting those that are now ignored
+// or aren't regular files.
+func (s *watchState) reclassify(names []string) {
+	infos := make(map[string]os.FileInfo)
+	var valid []string
+	for _, name := range names {
+		info, err := os.Lstat(name)
+		if err != nil || !info.Mode().IsRegular() || s.isIgnored(name) || isReuseMetadata(name) || isCustomLicenseText(name) {
+			delete(s.raw, name)
+			continue
+		}
+		infos[name] = info
+		valid = append(valid, name)
+	}
+
+	files := classifyAll(valid, func(name string) []License {
+		return classifyWith(name, infos[name].Size(), s.readLicenses)
+	})
+	for name, lics := range files {
+		s.raw[name] = lics
+	}
+}
+
+// evaluate applies inheritance from LICENSE files and the LICENSE
+// documentation check to the classified files, as scan does, and returns the
+// verdicts on the files that aren't ignored.
+func (s *watchState) evaluate() map[string]watchVerdict {
+	verdicts := make(map[string]watchVerdict)
+	for name, raw := range s.raw {
+		lics := resolveFile(name, raw, func(licPath string) []License { return s.raw[licPath] }, s.kind)
+		licStr, ignore, undoc := verdict(lics)
+		if !ignore {
+			verdicts[name] = watchVerdict{licStr, undoc}
+		}
+	}
+	return verdicts
+}
+
+// update checks the changed files, reloading the project and checking every
+// file if a project file changed, and writes the files that started or
+// stopped failing.
+func (s *watchState) update(w io.Writer, changed map[string]bool) {
+	reload := false
+	var names []string
+	for name := range changed {
+		if projectFile(name) {
+			reload = true
+		}
+		if path.Base(name) == `.gitignore` {
+			s.ignored = make(map[string]bool)
+		}
+		delete(s.contents, name)
+		delete(s.kinds, name)
+		delete(s.ignored, name)
+		names = append(names, name)
+		if strings.HasSuffix(name, reuseSidecarExt) {
+			names = append(names, strings.TrimSuffix(name, reuseSidecarExt))
+		}
+	}
+	if reload {
+		if err := reloadProject(); err != nil {
+			fmt.Fprintln(w, err.Error())
+			return
+		}
+		// The classifier and the configuration of large files may have
+		// changed, so no file's contents are judged as they were.
+		s.contents = make(map[string]watchResult)
+		s.kinds = make(map[string]string)
+		for name := range s.raw {
+			names = append(names, name)
+		}
+	}
+
+	var files []string
+	for _, name := range names {
+		info, err := os.Lstat(name)
+		switch {
+		case err == nil && info.IsDir():
+			files = append(files, s.walk(name)...)
+		case err != nil:
+			// A removed directory removes the files in it.
+			for known := range s.raw {
+				if strings.HasPrefix(known, name+`/`) {
+					files = append(files, known)
+				}
+			}
+			files = append(files, name)
+		default:
+			files = append(files, name)
+		}
+	}
+	s.reclassify(files)
+
+	verdicts := s.evaluate()
+	failing, newlyFailing, fixed := 0, 0, 0
+	for _, name := range sortedVerdicts(verdicts) {
+		v := verdicts[name]
+		if !v.Failed {
+			continue
+		}
+		failing++
+		if old, ok := s.verdicts[name]; !ok || !old.Failed || old.License != v.License {
+			fmt.Fprintf(w, "%-6s%40s %s\n", "Error", v.License, name)
+			newlyFailing++
+		}
+	}
+	for _, name := range sortedVerdicts(s.verdicts) {
+		if !s.verdicts[name].Failed {
+			continue
+		}
+		if v, ok := verdicts[name]; !ok {
+			fmt.Fprintf(w, "%-6s%40s %s\n", "Fixed", "(removed)", name)
+			fixed++
+		} else if !v.Failed {
+			fmt.Fprintf(w, "%-6s%40s %s\n", "Fixed", v.License, name)
+			fixed++
+		}
+	}
+	s.verdicts = verdicts
+	fmt.Fprintf(w, "%s %d changed, %d newly failing, %d fixed, %d failing\n", time.Now().Format(`15:04:05`), len(changed), newlyFailing, fixed, failing)
+}
+
+func sortedVerdicts(verdicts map[string]watchVerdict) []string {
+	var names []string
+	for name := range verdicts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
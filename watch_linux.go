@@ -0,0 +1,132 @@
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// inotifyMask selects the inotify events that change a file's contents or
+// existence.
+const inotifyMask = syscall.IN_CLOSE_WRITE | syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// inotifyWatcher watches every directory of the project with inotify.
+type inotifyWatcher struct {
+	fd     int
+	dirs   map[int32]string // By watch descriptor.
+	events chan string
+	errors chan error
+}
+
+// newFileWatcher watches the project in root, which must be the working
+// directory.
+func newFileWatcher(root string) (fileWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("inotify: %v", err)
+	}
+	w := &inotifyWatcher{
+		fd:     fd,
+		dirs:   make(map[int32]string),
+		events: make(chan string, 1024),
+		errors: make(chan error, 16),
+	}
+	if err := w.addTree(`.`); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *inotifyWatcher) Events() <-chan string { return w.events }
+func (w *inotifyWatcher) Errors() <-chan error  { return w.errors }
+
+// addTree watches dir and the directories below it, other than .git.
+func (w *inotifyWatcher) addTree(dir string) error {
+	return filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if filepath.Base(name) == `.git` {
+			return filepath.SkipDir
+		}
+		wd, err := syscall.InotifyAddWatch(w.fd, name, inotifyMask)
+		if err == syscall.ENOSPC {
+			return fmt.Errorf("Too many directories to watch, raise fs.inotify.max_user_watches")
+		}
+		if err != nil {
+			return fmt.Errorf("Cannot watch %s: %v", name, err)
+		}
+		w.dirs[int32(wd)] = name
+		return nil
+	})
+}
+
+// run reads inotify events until reading fails, watching new directories
+// as they are created.
+func (w *inotifyWatcher) run() {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := syscall.Read(w.fd, buf)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil || n <= 0 {
+			w.errors <- fmt.Errorf("inotify: %v", err)
+			return
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			start := offset + syscall.SizeofInotifyEvent
+			offset = start + int(event.Len)
+			name := strings.TrimRight(string(buf[start:offset]), "\x00")
+
+			switch {
+			case event.Mask&syscall.IN_Q_OVERFLOW != 0:
+				w.errors <- fmt.Errorf("inotify: too many changes at once, some were missed")
+				continue
+			case event.Mask&syscall.IN_IGNORED != 0:
+				delete(w.dirs, event.Wd)
+				continue
+			case name == ``:
+				continue
+			}
+
+			dir, ok := w.dirs[event.Wd]
+			if !ok || name == `.git` {
+				continue
+			}
+			p := path.Join(dir, name)
+			if event.Mask&syscall.IN_ISDIR != 0 && event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+				if err := w.addTree(p); err != nil {
+					w.errors <- err
+				}
+			}
+			w.events <- p
+		}
+	}
+}
@@ -0,0 +1,91 @@
+//go:build !linux
+// +build !linux
+
+/*
+Copyright 2026 Comcast Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pollInterval is how often the project is walked for changes where
+// inotify isn't available.
+const pollInterval = time.Second
+
+// pollWatcher finds changes by walking the project and comparing the sizes
+// and modification times of its files.
+type pollWatcher struct {
+	events chan string
+	errors chan error
+}
+
+type pollStamp struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// newFileWatcher watches the project in root, which must be the working
+// directory.
+func newFileWatcher(root string) (fileWatcher, error) {
+	w := &pollWatcher{events: make(chan string, 1024), errors: make(chan error, 16)}
+	go w.run(pollSnapshot())
+	return w, nil
+}
+
+func (w *pollWatcher) Events() <-chan string { return w.events }
+func (w *pollWatcher) Errors() <-chan error  { return w.errors }
+
+func (w *pollWatcher) run(last map[string]pollStamp) {
+	for range time.Tick(pollInterval) {
+		next := pollSnapshot()
+		for name, stamp := range next {
+			if old, ok := last[name]; !ok || old != stamp {
+				w.events <- name
+			}
+		}
+		for name := range last {
+			if _, ok := next[name]; !ok {
+				w.events <- name
+			}
+		}
+		last = next
+	}
+}
+
+// pollSnapshot records the size and modification time of every file in the
+// working directory, other than in .git.
+func pollSnapshot() map[string]pollStamp {
+	stamps := make(map[string]pollStamp)
+	filepath.Walk(`.`, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if filepath.Base(name) == `.git` {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() {
+			stamps[filepath.ToSlash(name)] = pollStamp{info.Size(), info.ModTime()}
+		}
+		return nil
+	})
+	return stamps
+}